@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/owen-gxz/open-wechat/core"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -18,6 +19,7 @@ type AccessTokenServer interface {
 }
 
 type DefaultAccessTokenServer struct {
+	mu        sync.Mutex
 	AppID     string
 	AppSecret string
 	ticket    TicketServer
@@ -26,8 +28,10 @@ type DefaultAccessTokenServer struct {
 	ExpiresIn            int64  `json:"expires_in"` // 当前时间 + 过期时间
 }
 
-// token不使用不获取
+// token不使用不获取, 并发调用时只有一个会去获取, 避免新token使其它token失效
 func (d *DefaultAccessTokenServer) Token() (token string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	timeUnix := time.Now().Unix()
 	if d.ExpiresIn <= time.Now().Unix() {
 		ticket, err := d.ticket.GetTicket()
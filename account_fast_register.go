@@ -0,0 +1,119 @@
+package open_wechat
+
+import (
+	"errors"
+	"fmt"
+	"github.com/owen-gxz/open-wechat/core"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// 复用公众号主体快速注册小程序
+const (
+	FastRegisterAuthPageUrl = "https://mp.weixin.qq.com/cgi-bin/fastregisterauth?component_appid=%s&appid=%s&copy_wx_verify=%d&redirect_uri=%s"
+	FastRegisterUrl         = wechatApiUrl + "/cgi-bin/account/fastregister?access_token=%s"
+
+	// 跳转地址上带的公众号appid参数名
+	FastRegisterAppidParam = "authorizer_appid"
+)
+
+// 公众号管理员确认的授权页链接, 确认后跳转到redirectUri并带上ticket参数
+// copyWxVerify 是否复用公众号的资质进行微信认证
+func (srv *Server) FastRegisterAuthUrl(authorizerAppid, redirectUri string, copyWxVerify bool) string {
+	if strings.Contains(redirectUri, "?") {
+		redirectUri += "&"
+	} else {
+		redirectUri += "?"
+	}
+	redirectUri += FastRegisterAppidParam + "=" + url.QueryEscape(authorizerAppid)
+	copyVerify := 0
+	if copyWxVerify {
+		copyVerify = 1
+	}
+	return fmt.Sprintf(FastRegisterAuthPageUrl, url.QueryEscape(srv.cfg.AppID), url.QueryEscape(authorizerAppid),
+		copyVerify, url.QueryEscape(redirectUri))
+}
+
+type FastRegisterRequest struct {
+	Ticket string `json:"ticket"`
+}
+
+type FastRegisterResponse struct {
+	core.Error
+	// 新创建的小程序appid
+	Appid string `json:"appid"`
+	// 新创建小程序的授权码
+	AuthorizationCode string `json:"authorization_code"`
+	// 复用公众号微信认证小程序是否成功
+	IsWxVerifySucc bool `json:"is_wx_verify_succ"`
+	// 小程序是否和公众号关联成功
+	IsLinkSucc bool `json:"is_link_succ"`
+}
+
+// 使用跳转带回的ticket注册小程序
+func (srv *Server) FastRegister(authorizerAppid, ticket string) (*FastRegisterResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeFastRegisterWeapp)
+	if err != nil {
+		return nil, err
+	}
+	req := FastRegisterRequest{
+		Ticket: ticket,
+	}
+	resp := &FastRegisterResponse{}
+	err = srv.PostJson(getCompleteUrl(FastRegisterUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type FastRegisterByOfficialAccountResult struct {
+	// 公众号appid
+	AuthorizerAppid string
+	FastRegister    *FastRegisterResponse
+	// 新小程序的授权信息
+	QueryAuth *QueryAuthResponse
+}
+
+// 注册小程序并使用返回的授权码调用QueryAuth
+func (srv *Server) FastRegisterByOfficialAccount(authorizerAppid, ticket string) (*FastRegisterByOfficialAccountResult, error) {
+	fastRegister, err := srv.FastRegister(authorizerAppid, ticket)
+	if err != nil {
+		return nil, err
+	}
+	if err = fastRegister.Err(); err != nil {
+		return nil, err
+	}
+	queryAuth, err := srv.QueryAuth(fastRegister.AuthorizationCode)
+	if err != nil {
+		return nil, err
+	}
+	if err = queryAuth.Err(); err != nil {
+		return nil, err
+	}
+	return &FastRegisterByOfficialAccountResult{
+		AuthorizerAppid: authorizerAppid,
+		FastRegister:    fastRegister,
+		QueryAuth:       queryAuth,
+	}, nil
+}
+
+// 处理 FastRegisterAuthUrl 确认后的跳转, 完成注册和授权后调用fn, 出错时err不为nil
+func (srv *Server) FastRegisterRedirectHandler(fn func(w http.ResponseWriter, r *http.Request, result *FastRegisterByOfficialAccountResult, err error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		authorizerAppid := query.Get(FastRegisterAppidParam)
+		if authorizerAppid == "" {
+			fn(w, r, nil, errors.New("not found "+FastRegisterAppidParam+" query parameter"))
+			return
+		}
+		ticket := query.Get("ticket")
+		if ticket == "" {
+			fn(w, r, nil, errors.New("not found ticket query parameter"))
+			return
+		}
+		result, err := srv.FastRegisterByOfficialAccount(authorizerAppid, ticket)
+		fn(w, r, result, err)
+	})
+}
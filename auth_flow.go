@@ -0,0 +1,219 @@
+package open_wechat
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// 授权回调地址上state参数名
+	AuthFlowStateParam = "state"
+
+	defaultAuthFlowStateExpires = 30 * time.Minute
+)
+
+var (
+	ErrAuthFlowState  = errors.New("invalid or expired state")
+	ErrAuthFlowTenant = errors.New("state tenant mismatch")
+)
+
+// 授权完成后的结果
+type AuthorizationResult struct {
+	Tenant          string
+	AuthorizerAppid string
+	QueryAuth       *QueryAuthResponse
+	AuthorizerInfo  *AuthorizerInfoResponse
+}
+
+// 保存租户与授权方的关系, 授权方的token已通过QueryAuth保存到授权方token存储
+type AuthorizationStore interface {
+	SaveAuthorization(result *AuthorizationResult) error
+}
+
+type AuthorizedFunc func(w http.ResponseWriter, r *http.Request, result *AuthorizationResult)
+
+// 获取发起授权的租户id, 需要从已登录的会话中获取, 不要使用请求参数
+type TenantFunc func(r *http.Request) (string, error)
+
+// AuthFlow 授权流程:
+// StartHandler 生成带签名state的授权链接并跳转, CallbackHandler 校验state, 换取授权信息并保存
+type AuthFlow struct {
+	srv    *Server
+	secret []byte
+	// 授权后跳转的地址, 需要指向 CallbackHandler
+	redirectUri  string
+	store        AuthorizationStore
+	onAuthorized AuthorizedFunc
+	tenant       TenantFunc
+
+	// 授权链接的类型, 默认 PreAuthAuthTypeAll
+	AuthType AuthType
+	// 生成移动端授权链接
+	Mobile bool
+	// state有效期, 默认30分钟
+	StateExpires time.Duration
+	// 错误处理, 默认 DefaultErrorHandler
+	ErrorHandler WechatErrorer
+}
+
+// secret 用于state签名, 不能为空; tenant 获取发起授权的租户id, 不能为nil; store和onAuthorized可以为nil
+func NewAuthFlow(srv *Server, secret []byte, redirectUri string, tenant TenantFunc, store AuthorizationStore, onAuthorized AuthorizedFunc) (*AuthFlow, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("auth flow secret is empty")
+	}
+	if tenant == nil {
+		return nil, errors.New("auth flow tenant func is nil")
+	}
+	return &AuthFlow{
+		srv:          srv,
+		secret:       secret,
+		redirectUri:  redirectUri,
+		store:        store,
+		onAuthorized: onAuthorized,
+		tenant:       tenant,
+		AuthType:     PreAuthAuthTypeAll,
+		StateExpires: defaultAuthFlowStateExpires,
+		ErrorHandler: DefaultErrorHandler,
+	}, nil
+}
+
+// 发起授权, 跳转到授权页
+func (f *AuthFlow) StartHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant, err := f.tenant(r)
+		if err != nil {
+			f.ErrorHandler.ServeError(w, r, err)
+			return
+		}
+		redirectUri := f.redirectUri
+		if strings.Contains(redirectUri, "?") {
+			redirectUri += "&"
+		} else {
+			redirectUri += "?"
+		}
+		redirectUri += AuthFlowStateParam + "=" + f.signState(tenant, time.Now().Unix())
+		link, err := f.srv.AuthLink(AuthLinkRequest{
+			RedirectUri: redirectUri,
+			AuthType:    f.AuthType,
+			Mobile:      f.Mobile,
+		})
+		if err != nil {
+			f.ErrorHandler.ServeError(w, r, err)
+			return
+		}
+		http.Redirect(w, r, link, http.StatusFound)
+	})
+}
+
+// 授权后的跳转, 微信会带上auth_code参数
+func (f *AuthFlow) CallbackHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		tenant, err := f.verifyState(query.Get(AuthFlowStateParam))
+		if err != nil {
+			f.ErrorHandler.ServeError(w, r, err)
+			return
+		}
+		// 完成授权的需要是发起授权的租户, 防止把授权链接发给其他人授权
+		sessionTenant, err := f.tenant(r)
+		if err != nil {
+			f.ErrorHandler.ServeError(w, r, err)
+			return
+		}
+		if sessionTenant != tenant {
+			f.ErrorHandler.ServeError(w, r, ErrAuthFlowTenant)
+			return
+		}
+		authCode := query.Get("auth_code")
+		if authCode == "" {
+			f.ErrorHandler.ServeError(w, r, errors.New("not found auth_code query parameter"))
+			return
+		}
+		result, err := f.authorize(tenant, authCode)
+		if err != nil {
+			f.ErrorHandler.ServeError(w, r, err)
+			return
+		}
+		if f.onAuthorized != nil {
+			f.onAuthorized(w, r, result)
+			return
+		}
+		w.Write(Success)
+	})
+}
+
+func (f *AuthFlow) authorize(tenant, authCode string) (*AuthorizationResult, error) {
+	queryAuth, err := f.srv.QueryAuth(authCode)
+	if err != nil {
+		return nil, err
+	}
+	if err = queryAuth.Err(); err != nil {
+		return nil, err
+	}
+	authorizerAppid := queryAuth.AuthorizationInfo.AuthorizerAppid
+	info, err := f.srv.AuthorizerInfo(authorizerAppid)
+	if err != nil {
+		return nil, err
+	}
+	if err = info.Err(); err != nil {
+		return nil, err
+	}
+	result := &AuthorizationResult{
+		Tenant:          tenant,
+		AuthorizerAppid: authorizerAppid,
+		QueryAuth:       queryAuth,
+		AuthorizerInfo:  info,
+	}
+	if f.store != nil {
+		if err = f.store.SaveAuthorization(result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// state = base64(tenant).timestamp.hmac
+func (f *AuthFlow) signState(tenant string, timestamp int64) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(tenant)) + "." + strconv.FormatInt(timestamp, 10)
+	return payload + "." + f.stateMac(payload)
+}
+
+func (f *AuthFlow) stateMac(payload string) string {
+	mac := hmac.New(sha256.New, f.secret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (f *AuthFlow) verifyState(state string) (string, error) {
+	i := strings.LastIndex(state, ".")
+	if i < 0 {
+		return "", ErrAuthFlowState
+	}
+	payload, sign := state[:i], state[i+1:]
+	if !hmac.Equal([]byte(sign), []byte(f.stateMac(payload))) {
+		return "", ErrAuthFlowState
+	}
+	parts := strings.SplitN(payload, ".", 2)
+	if len(parts) != 2 {
+		return "", ErrAuthFlowState
+	}
+	timestamp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", ErrAuthFlowState
+	}
+	if time.Since(time.Unix(timestamp, 0)) > f.StateExpires {
+		return "", ErrAuthFlowState
+	}
+	tenant, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", ErrAuthFlowState
+	}
+	return string(tenant), nil
+}
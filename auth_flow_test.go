@@ -0,0 +1,78 @@
+package open_wechat
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestAuthFlow(t *testing.T, tenant string) *AuthFlow {
+	srv := newTestServer(t, "wxcomponent", "token", strings.Repeat("a", 43))
+	f, err := NewAuthFlow(srv, []byte("secret"), "https://example.com/callback", func(r *http.Request) (string, error) {
+		return tenant, nil
+	}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestNewAuthFlowRequiresSecretAndTenant(t *testing.T) {
+	srv := newTestServer(t, "wxcomponent", "token", strings.Repeat("a", 43))
+	tenant := func(r *http.Request) (string, error) { return "tenant", nil }
+	if _, err := NewAuthFlow(srv, nil, "https://example.com/callback", tenant, nil, nil); err == nil {
+		t.Fatal("empty secret accepted")
+	}
+	if _, err := NewAuthFlow(srv, []byte("secret"), "https://example.com/callback", nil, nil, nil); err == nil {
+		t.Fatal("nil tenant func accepted")
+	}
+}
+
+func TestAuthFlowState(t *testing.T) {
+	f := newTestAuthFlow(t, "tenant")
+	state := f.signState("tenant", time.Now().Unix())
+	tenant, err := f.verifyState(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tenant != "tenant" {
+		t.Fatalf("tenant %q, want %q", tenant, "tenant")
+	}
+
+	expired := f.signState("tenant", time.Now().Add(-f.StateExpires-time.Minute).Unix())
+	if _, err = f.verifyState(expired); err != ErrAuthFlowState {
+		t.Fatalf("expired state: %v", err)
+	}
+
+	i := strings.LastIndex(state, ".")
+	tampered := f.signState("other", time.Now().Unix())
+	tampered = tampered[:strings.LastIndex(tampered, ".")] + state[i:]
+	if _, err = f.verifyState(tampered); err != ErrAuthFlowState {
+		t.Fatalf("tampered state: %v", err)
+	}
+
+	other := newTestAuthFlow(t, "tenant")
+	other.secret = []byte("other secret")
+	if _, err = other.verifyState(state); err != ErrAuthFlowState {
+		t.Fatalf("state signed with another secret: %v", err)
+	}
+}
+
+func TestAuthFlowCallbackTenantMismatch(t *testing.T) {
+	f := newTestAuthFlow(t, "victim")
+	var gotErr error
+	f.ErrorHandler = ErrorHandlerFunc(func(w http.ResponseWriter, r *http.Request, err error) {
+		gotErr = err
+	})
+	query := url.Values{
+		AuthFlowStateParam: {f.signState("attacker", time.Now().Unix())},
+		"auth_code":        {"code"},
+	}
+	f.CallbackHandler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/callback?"+query.Encode(), nil))
+	if gotErr != ErrAuthFlowTenant {
+		t.Fatalf("error %v, want %v", gotErr, ErrAuthFlowTenant)
+	}
+}
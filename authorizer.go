@@ -1,6 +1,10 @@
 package open_wechat
 
-import "github.com/owen-gxz/open-wechat/core"
+import (
+	"fmt"
+	"github.com/owen-gxz/open-wechat/core"
+	"time"
+)
 
 // 授权方信息
 const (
@@ -10,8 +14,66 @@ const (
 	AuthorizerListUrl      = wechatApiUrl + "/cgi-bin/component/api_get_authorizer_list?component_access_token=%s"
 )
 
+// 授权方详情
 type AuthorizerInfo struct {
+	// 小程序独有
+	Signature string `json:"signature"`
+	// 公众号没有该字段
+	Miniprograminfo *MiniProgramInfo `json:"miniprograminfo"`
+
+	// 都存在的
+	//昵称
+	NickName string `json:"nick_name"`
+	HeadImg  string `json:"head_img"`
+	//公众号类型  --公众号独有
+	ServiceTypeInfo struct {
+		ID ServiceType `json:"id"`
+	} `json:"service_type_info"`
+	// 认证类型
+	VerifyTypeInfo struct {
+		ID VerifyType `json:"id"`
+	} `json:"verify_type_info"`
+	//原始 ID
+	UserName string `json:"user_name"`
+	// 主题名称
+	PrincipalName string `json:"principal_name"`
+	//用以了解功能的开通状况（0代表未开通，1代表已开通），详见business_info 说明
+	BusinessInfo BusinessInfo `json:"business_info"`
+	Alias        string       `json:"alias"`
+	//二维码图片的 URL，开发者最好自行也进行保存
+	QrcodeURL string `json:"qrcode_url"`
+}
+
+type MiniProgramInfo struct {
+	Network     MiniProgramNetwork    `json:"network"`
+	Categories  []MiniProgramCategory `json:"categories"`
+	VisitStatus int                   `json:"visit_status"`
+}
+
+// 小程序服务器域名, 授权方详情中返回的key为大写开头, json解析不区分大小写
+// 修改域名接口需要小写的key, 见 ModifyDomain
+type MiniProgramNetwork struct {
+	RequestDomain   []string `json:"requestdomain,omitempty"`
+	WsRequestDomain []string `json:"wsrequestdomain,omitempty"`
+	UploadDomain    []string `json:"uploaddomain,omitempty"`
+	DownloadDomain  []string `json:"downloaddomain,omitempty"`
+	UdpDomain       []string `json:"udpdomain,omitempty"`
+	TcpDomain       []string `json:"tcpdomain,omitempty"`
+}
+
+type MiniProgramCategory struct {
+	First  string `json:"first"`
+	Second string `json:"second"`
+}
+
+type BusinessInfo struct {
+	OpenStore int `json:"open_store"`
+	OpenScan  int `json:"open_scan"`
+	OpenPay   int `json:"open_pay"`
+	OpenCard  int `json:"open_card"`
+	OpenShake int `json:"open_shake"`
 }
+
 type AuthorizerInfoRequest struct {
 	ComponentAppid  string `json:"component_appid"`
 	AuthorizerAppid string `json:"authorizer_appid"`
@@ -19,63 +81,15 @@ type AuthorizerInfoRequest struct {
 
 type AuthorizerInfoResponse struct {
 	core.Error
-	AuthorizerInfo struct {
-		// 小程序独有
-		Signature       string `json:"signature"`
-		Miniprograminfo struct {
-			Network struct {
-				RequestDomain   []string `json:"RequestDomain"`
-				WsRequestDomain []string `json:"WsRequestDomain"`
-				UploadDomain    []string `json:"UploadDomain"`
-				DownloadDomain  []string `json:"DownloadDomain"`
-			} `json:"network"`
-			Categories []struct {
-				First  string `json:"first"`
-				Second string `json:"second"`
-			} `json:"categories"`
-			VisitStatus int `json:"visit_status"`
-		} `json:"miniprograminfo"`
-
-		// 都存在的
-		//昵称
-		NickName        string `json:"nick_name"`
-		HeadImg         string `json:"head_img"`
-		//公众号类型  --公众号独有
-		ServiceTypeInfo struct {
-			ID int `json:"id"`
-		} `json:"service_type_info"`
-		// 认证类型
-		VerifyTypeInfo struct {
-			ID int `json:"id"`
-		} `json:"verify_type_info"`
-		//原始 ID
-		UserName      string `json:"user_name"`
-		// 主题名称
-		PrincipalName string `json:"principal_name"`
-		//用以了解功能的开通状况（0代表未开通，1代表已开通），详见business_info 说明
-		BusinessInfo  struct {
-			OpenStore int `json:"open_store"`
-			OpenScan  int `json:"open_scan"`
-			OpenPay   int `json:"open_pay"`
-			OpenCard  int `json:"open_card"`
-			OpenShake int `json:"open_shake"`
-		} `json:"business_info"`
-		Alias     string `json:"alias"`
-		//二维码图片的 URL，开发者最好自行也进行保存
-		QrcodeURL string `json:"qrcode_url"`
-	} `json:"authorizer_info"`
+	AuthorizerInfo    AuthorizerInfo `json:"authorizer_info"`
 	AuthorizationInfo struct {
-		AuthorizationAppid string `json:"authorization_appid"`
-		FuncInfo           []struct {
-			FuncscopeCategory struct {
-				ID int `json:"id"`
-			} `json:"funcscope_category"`
-		} `json:"func_info"`
+		AuthorizationAppid string       `json:"authorization_appid"`
+		FuncInfo           FuncInfoList `json:"func_info"`
 	} `json:"authorization_info"`
 }
 
 // 获取授权法信息
-func (srv Server) AuthorizerInfo(authorizerAppid string) (*AuthorizerInfoResponse, error) {
+func (srv *Server) AuthorizerInfo(authorizerAppid string) (*AuthorizerInfoResponse, error) {
 	accessToken, err := srv.Token()
 	if err != nil {
 		return nil, err
@@ -101,6 +115,35 @@ var (
 	AuthorizeOptionCustomerService AuthorizeOption = "customer_service"
 )
 
+type AuthorizeOptionValue string
+
+// option的值
+const (
+	LocationReportOff       AuthorizeOptionValue = "0" // 地理位置无上报
+	LocationReportOnSession AuthorizeOptionValue = "1" // 进入会话时上报地理位置
+	LocationReportEvery5s   AuthorizeOptionValue = "2" // 每5s上报地理位置
+	VoiceRecognizeOff       AuthorizeOptionValue = "0" // 关闭语音识别
+	VoiceRecognizeOn        AuthorizeOptionValue = "1" // 开启语音识别
+	CustomerServiceOff      AuthorizeOptionValue = "0" // 关闭多客服
+	CustomerServiceOn       AuthorizeOptionValue = "1" // 开启多客服
+)
+
+var authorizeOptionValues = map[AuthorizeOption][]AuthorizeOptionValue{
+	AuthorizeOptionLocal:           {LocationReportOff, LocationReportOnSession, LocationReportEvery5s},
+	AuthorizeOptionVoiceRecognize:  {VoiceRecognizeOff, VoiceRecognizeOn},
+	AuthorizeOptionCustomerService: {CustomerServiceOff, CustomerServiceOn},
+}
+
+// 是否option可以设置的值
+func (option AuthorizeOption) Valid(value AuthorizeOptionValue) bool {
+	for _, v := range authorizeOptionValues[option] {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 type AuthorizerOptionRequest struct {
 	ComponentAppid  string          `json:"component_appid"`
 	AuthorizerAppid string          `json:"authorizer_appid"`
@@ -109,13 +152,13 @@ type AuthorizerOptionRequest struct {
 
 type AuthorizerOptionResponse struct {
 	core.Error
-	AuthorizerAppid string `json:"authorizer_appid"`
-	OptionName      string `json:"option_name"`
-	OptionValue     string `json:"option_value"`
+	AuthorizerAppid string               `json:"authorizer_appid"`
+	OptionName      AuthorizeOption      `json:"option_name"`
+	OptionValue     AuthorizeOptionValue `json:"option_value"`
 }
 
 // 获取选项信息
-func (srv Server) AuthorizerOption(authorizerAppid string, optionName AuthorizeOption) (*AuthorizerOptionResponse, error) {
+func (srv *Server) AuthorizerOption(authorizerAppid string, optionName AuthorizeOption) (*AuthorizerOptionResponse, error) {
 	accessToken, err := srv.Token()
 	if err != nil {
 		return nil, err
@@ -135,7 +178,7 @@ func (srv Server) AuthorizerOption(authorizerAppid string, optionName AuthorizeO
 
 type SetAuthorizerOptionRequest struct {
 	AuthorizerOptionRequest
-	OptionValue string `json:"option_name"`
+	OptionValue AuthorizeOptionValue `json:"option_value"`
 }
 
 type SetAuthorizerOptionResponse struct {
@@ -143,7 +186,10 @@ type SetAuthorizerOptionResponse struct {
 }
 
 // 设置选项信息
-func (srv Server) SetAuthorizerOption(authorizerAppid string, optionName AuthorizeOption, optionValue string) (*SetAuthorizerOptionResponse, error) {
+func (srv *Server) SetAuthorizerOption(authorizerAppid string, optionName AuthorizeOption, optionValue AuthorizeOptionValue) (*SetAuthorizerOptionResponse, error) {
+	if !optionName.Valid(optionValue) {
+		return nil, fmt.Errorf("invalid value %q for option %s", optionValue, optionName)
+	}
 	accessToken, err := srv.Token()
 	if err != nil {
 		return nil, err
@@ -164,24 +210,72 @@ func (srv Server) SetAuthorizerOption(authorizerAppid string, optionName Authori
 	return resp, nil
 }
 
+type AuthorizerOptionResult struct {
+	AuthorizerAppid string
+	OptionValue     AuthorizeOptionValue
+	// 请求失败或errcode不为0
+	Err error
+}
+
+// 批量获取选项信息
+func (srv *Server) BatchAuthorizerOption(authorizerAppids []string, optionName AuthorizeOption) []AuthorizerOptionResult {
+	results := make([]AuthorizerOptionResult, len(authorizerAppids))
+	for i, appid := range authorizerAppids {
+		results[i].AuthorizerAppid = appid
+		resp, err := srv.AuthorizerOption(appid, optionName)
+		if err == nil {
+			err = resp.Err()
+		}
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].OptionValue = resp.OptionValue
+	}
+	return results
+}
+
+// 批量设置选项信息
+func (srv *Server) BatchSetAuthorizerOption(authorizerAppids []string, optionName AuthorizeOption, optionValue AuthorizeOptionValue) []AuthorizerOptionResult {
+	results := make([]AuthorizerOptionResult, len(authorizerAppids))
+	for i, appid := range authorizerAppids {
+		results[i].AuthorizerAppid = appid
+		resp, err := srv.SetAuthorizerOption(appid, optionName, optionValue)
+		if err == nil {
+			err = resp.Err()
+		}
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].OptionValue = optionValue
+	}
+	return results
+}
+
 type AuthorizerListRequest struct {
 	ComponentAppid string `json:"component_appid"`
 	Offset         int    `json:"offset"`
 	Count          int    `json:"count"`
 }
 
+// 拉取授权列表每页数量, 最大500
+const authorizerListMaxCount = 500
+
+type AuthorizerListItem struct {
+	AuthorizerAppid string `json:"authorizer_appid"`
+	RefreshToken    string `json:"refresh_token"`
+	AuthTime        int    `json:"auth_time"`
+}
+
 type AuthorizerListResponse struct {
 	core.Error
-	TotalCount int `json:"total_count"`
-	List       []struct {
-		AuthorizerAppid string `json:"authorizer_appid"`
-		RefreshToken    string `json:"refresh_token"`
-		AuthTime        int    `json:"auth_time"`
-	} `json:"list"`
+	TotalCount int                  `json:"total_count"`
+	List       []AuthorizerListItem `json:"list"`
 }
 
 // 拉取用户授权列表
-func (srv Server) AuthorizerList(offset, count int) (*AuthorizerListResponse, error) {
+func (srv *Server) AuthorizerList(offset, count int) (*AuthorizerListResponse, error) {
 	accessToken, err := srv.Token()
 	if err != nil {
 		return nil, err
@@ -198,3 +292,52 @@ func (srv Server) AuthorizerList(offset, count int) (*AuthorizerListResponse, er
 	}
 	return resp, nil
 }
+
+// 遍历全部授权方, pageSize最大500, 每页请求间隔interval, fn返回false时停止
+func (srv *Server) RangeAuthorizers(pageSize int, interval time.Duration, fn func(item AuthorizerListItem) bool) error {
+	if pageSize <= 0 || pageSize > authorizerListMaxCount {
+		pageSize = authorizerListMaxCount
+	}
+	for offset := 0; ; offset += pageSize {
+		if offset > 0 && interval > 0 {
+			time.Sleep(interval)
+		}
+		resp, err := srv.AuthorizerList(offset, pageSize)
+		if err != nil {
+			return err
+		}
+		if err = resp.Err(); err != nil {
+			return err
+		}
+		for _, item := range resp.List {
+			if !fn(item) {
+				return nil
+			}
+		}
+		if len(resp.List) == 0 || offset+len(resp.List) >= resp.TotalCount {
+			return nil
+		}
+	}
+}
+
+// 以channel的形式遍历全部授权方, 结束或关闭stop后关闭items, 出错时错误写入errs
+func (srv *Server) AuthorizerStream(pageSize int, interval time.Duration, stop <-chan struct{}) (<-chan AuthorizerListItem, <-chan error) {
+	items := make(chan AuthorizerListItem)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(items)
+		defer close(errs)
+		err := srv.RangeAuthorizers(pageSize, interval, func(item AuthorizerListItem) bool {
+			select {
+			case items <- item:
+				return true
+			case <-stop:
+				return false
+			}
+		})
+		if err != nil {
+			errs <- err
+		}
+	}()
+	return items, errs
+}
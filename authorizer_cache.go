@@ -0,0 +1,77 @@
+package open_wechat
+
+import (
+	"sync"
+	"time"
+)
+
+type authorizerInfoCacheItem struct {
+	info      *AuthorizerInfoResponse
+	expiresAt time.Time
+}
+
+// AuthorizerInfoCache 缓存授权方详情, updateauthorized/unauthorized 推送时自动失效
+type AuthorizerInfoCache struct {
+	sync.Mutex
+	srv   *Server
+	ttl   time.Duration
+	items map[string]authorizerInfoCacheItem
+	// 每次失效加1, 拉取期间失效的结果不缓存
+	generations map[string]uint64
+}
+
+// 创建缓存并设置为Server的授权方详情缓存, 见 Server.CachedAuthorizerInfo
+func NewAuthorizerInfoCache(srv *Server, ttl time.Duration) *AuthorizerInfoCache {
+	c := &AuthorizerInfoCache{
+		srv:         srv,
+		ttl:         ttl,
+		items:       make(map[string]authorizerInfoCacheItem),
+		generations: make(map[string]uint64),
+	}
+	invalidate := func(msg *MixedMsg) {
+		c.Invalidate(msg.AuthorizerAppid)
+	}
+	srv.AddObserver(InfoTypeUpdateAuthorized, invalidate)
+	srv.AddObserver(InfoTypeUnauthorized, invalidate)
+	srv.authorizerInfoCache = c
+	return c
+}
+
+// 获取授权方详情, 只缓存成功的返回
+func (c *AuthorizerInfoCache) AuthorizerInfo(authorizerAppid string) (*AuthorizerInfoResponse, error) {
+	c.Lock()
+	item, ok := c.items[authorizerAppid]
+	generation := c.generations[authorizerAppid]
+	c.Unlock()
+	if ok && time.Now().Before(item.expiresAt) {
+		return item.info, nil
+	}
+	info, err := c.srv.AuthorizerInfo(authorizerAppid)
+	if err != nil {
+		return nil, err
+	}
+	if info.ErrCode == 0 {
+		c.Lock()
+		if c.generations[authorizerAppid] == generation {
+			c.items[authorizerAppid] = authorizerInfoCacheItem{info: info, expiresAt: time.Now().Add(c.ttl)}
+		}
+		c.Unlock()
+	}
+	return info, nil
+}
+
+// 删除授权方的缓存
+func (c *AuthorizerInfoCache) Invalidate(authorizerAppid string) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.items, authorizerAppid)
+	c.generations[authorizerAppid]++
+}
+
+// 获取授权方详情, 设置了 AuthorizerInfoCache 时使用缓存
+func (srv *Server) CachedAuthorizerInfo(authorizerAppid string) (*AuthorizerInfoResponse, error) {
+	if srv.authorizerInfoCache == nil {
+		return srv.AuthorizerInfo(authorizerAppid)
+	}
+	return srv.authorizerInfoCache.AuthorizerInfo(authorizerAppid)
+}
@@ -0,0 +1,234 @@
+package open_wechat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type AuthorizerType int
+
+// 授权方类型
+const (
+	AuthorizerTypeOfficialAccount AuthorizerType = 1 // 公众号
+	AuthorizerTypeMiniProgram     AuthorizerType = 2 // 小程序
+)
+
+type AuthorizerStatus int
+
+// 授权状态
+const (
+	AuthorizerStatusAuthorized   AuthorizerStatus = 1 // 已授权
+	AuthorizerStatusUnauthorized AuthorizerStatus = 2 // 已取消授权
+)
+
+// 注册表中的授权方
+type RegisteredAuthorizer struct {
+	AuthorizerAppid string
+	// 原始 ID
+	UserName  string
+	NickName  string
+	Type      AuthorizerType
+	Status    AuthorizerStatus
+	AuthTime  int64
+	UpdatedAt time.Time
+	// 最后一次状态变化对应的事件时间, 用于忽略比取消授权更早的刷新
+	statusTime int64
+}
+
+// AuthorizerRegistry 本地的授权方列表, 通过 Sync 从微信拉取,
+// authorized/updateauthorized/unauthorized 推送会自动更新
+type AuthorizerRegistry struct {
+	sync.RWMutex
+	srv         *Server
+	authorizers map[string]*RegisteredAuthorizer
+}
+
+func NewAuthorizerRegistry(srv *Server) *AuthorizerRegistry {
+	reg := &AuthorizerRegistry{
+		srv:         srv,
+		authorizers: make(map[string]*RegisteredAuthorizer),
+	}
+	// 推送需要在5秒内返回, 拉取授权信息放到goroutine中
+	refresh := func(msg *MixedMsg) {
+		go func(appid string, eventTime int64) {
+			if err := reg.refresh(appid, eventTime, 0); err != nil {
+				errorLogger.Output(2, err.Error())
+			}
+		}(msg.AuthorizerAppid, msg.CreateTime)
+	}
+	srv.AddObserver(InfoTypeAuthorized, refresh)
+	srv.AddObserver(InfoTypeUpdateAuthorized, refresh)
+	srv.AddObserver(InfoTypeUnauthorized, func(msg *MixedMsg) {
+		reg.setUnauthorized(msg.AuthorizerAppid, msg.CreateTime)
+	})
+	return reg
+}
+
+// 拉取授权方详情并更新
+func (reg *AuthorizerRegistry) Refresh(authorizerAppid string) error {
+	return reg.refresh(authorizerAppid, time.Now().Unix(), 0)
+}
+
+// eventTime之后已取消授权的不会被改回已授权, authTime为0时保留原来的授权时间
+func (reg *AuthorizerRegistry) refresh(authorizerAppid string, eventTime, authTime int64) error {
+	info, err := reg.srv.AuthorizerInfo(authorizerAppid)
+	if err != nil {
+		return err
+	}
+	if err = info.Err(); err != nil {
+		return err
+	}
+	reg.Lock()
+	defer reg.Unlock()
+	authorizer, ok := reg.authorizers[authorizerAppid]
+	if !ok {
+		authorizer = &RegisteredAuthorizer{AuthorizerAppid: authorizerAppid, AuthTime: time.Now().Unix()}
+		reg.authorizers[authorizerAppid] = authorizer
+	}
+	if authorizer.Status == AuthorizerStatusUnauthorized && authorizer.statusTime > eventTime {
+		return nil
+	}
+	authorizer.UserName = info.AuthorizerInfo.UserName
+	authorizer.NickName = info.AuthorizerInfo.NickName
+	authorizer.Type = info.AuthorizerInfo.Type()
+	authorizer.Status = AuthorizerStatusAuthorized
+	if authTime > 0 {
+		authorizer.AuthTime = authTime
+	}
+	authorizer.statusTime = eventTime
+	authorizer.UpdatedAt = time.Now()
+	return nil
+}
+
+// eventTime之后重新授权的不会被改为已取消授权
+func (reg *AuthorizerRegistry) setUnauthorized(authorizerAppid string, eventTime int64) {
+	reg.Lock()
+	defer reg.Unlock()
+	authorizer, ok := reg.authorizers[authorizerAppid]
+	if !ok {
+		authorizer = &RegisteredAuthorizer{AuthorizerAppid: authorizerAppid}
+		reg.authorizers[authorizerAppid] = authorizer
+	}
+	if authorizer.statusTime > eventTime {
+		return
+	}
+	authorizer.Status = AuthorizerStatusUnauthorized
+	authorizer.statusTime = eventTime
+	authorizer.UpdatedAt = time.Now()
+}
+
+// Sync 中拉取失败的授权方, key为appid
+type AuthorizerSyncError map[string]error
+
+func (e AuthorizerSyncError) Error() string {
+	appids := make([]string, 0, len(e))
+	for appid := range e {
+		appids = append(appids, appid)
+	}
+	sort.Strings(appids)
+	msgs := make([]string, len(appids))
+	for i, appid := range appids {
+		msgs[i] = appid + ": " + e[appid].Error()
+	}
+	return fmt.Sprintf("sync %d authorizers failed: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// 从微信拉取全部授权方, 不在授权列表中的标记为已取消授权
+// 只拉取新增的和授权时间变化的授权方详情, 单个授权方失败不影响其它授权方, 失败的通过 AuthorizerSyncError 返回
+func (reg *AuthorizerRegistry) Sync() error {
+	syncTime := time.Now().Unix()
+	authorized := make(map[string]bool)
+	syncErr := make(AuthorizerSyncError)
+	err := reg.srv.RangeAuthorizers(authorizerListMaxCount, 0, func(item AuthorizerListItem) bool {
+		authorized[item.AuthorizerAppid] = true
+		authorizer, ok := reg.Get(item.AuthorizerAppid)
+		if ok && authorizer.Status == AuthorizerStatusAuthorized && authorizer.AuthTime == int64(item.AuthTime) {
+			return true
+		}
+		if err := reg.refresh(item.AuthorizerAppid, syncTime, int64(item.AuthTime)); err != nil {
+			syncErr[item.AuthorizerAppid] = err
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	for _, authorizer := range reg.List() {
+		if !authorized[authorizer.AuthorizerAppid] && authorizer.Status == AuthorizerStatusAuthorized {
+			reg.setUnauthorized(authorizer.AuthorizerAppid, syncTime)
+		}
+	}
+	if len(syncErr) > 0 {
+		return syncErr
+	}
+	return nil
+}
+
+// 每隔interval调用一次Sync, 用于补上丢失的推送, 关闭stop后返回
+func (reg *AuthorizerRegistry) Reconcile(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := reg.Sync(); err != nil {
+				errorLogger.Output(2, err.Error())
+			}
+		}
+	}
+}
+
+// 按appid查找
+func (reg *AuthorizerRegistry) Get(authorizerAppid string) (RegisteredAuthorizer, bool) {
+	reg.RLock()
+	defer reg.RUnlock()
+	authorizer, ok := reg.authorizers[authorizerAppid]
+	if !ok {
+		return RegisteredAuthorizer{}, false
+	}
+	return *authorizer, true
+}
+
+// 按原始ID查找
+func (reg *AuthorizerRegistry) GetByUserName(userName string) (RegisteredAuthorizer, bool) {
+	reg.RLock()
+	defer reg.RUnlock()
+	for _, authorizer := range reg.authorizers {
+		if authorizer.UserName == userName {
+			return *authorizer, true
+		}
+	}
+	return RegisteredAuthorizer{}, false
+}
+
+// 全部授权方, 包括已取消授权的
+func (reg *AuthorizerRegistry) List() []RegisteredAuthorizer {
+	return reg.filter(func(*RegisteredAuthorizer) bool { return true })
+}
+
+// 按类型查找
+func (reg *AuthorizerRegistry) ListByType(t AuthorizerType) []RegisteredAuthorizer {
+	return reg.filter(func(authorizer *RegisteredAuthorizer) bool { return authorizer.Type == t })
+}
+
+// 按授权状态查找
+func (reg *AuthorizerRegistry) ListByStatus(status AuthorizerStatus) []RegisteredAuthorizer {
+	return reg.filter(func(authorizer *RegisteredAuthorizer) bool { return authorizer.Status == status })
+}
+
+func (reg *AuthorizerRegistry) filter(fn func(*RegisteredAuthorizer) bool) []RegisteredAuthorizer {
+	reg.RLock()
+	defer reg.RUnlock()
+	authorizers := make([]RegisteredAuthorizer, 0, len(reg.authorizers))
+	for _, authorizer := range reg.authorizers {
+		if fn(authorizer) {
+			authorizers = append(authorizers, *authorizer)
+		}
+	}
+	return authorizers
+}
@@ -0,0 +1,146 @@
+package open_wechat
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrAuthorizerTokenNotFound = errors.New("authorizer token not found")
+
+// 授权方的token信息
+type AuthorizerToken struct {
+	AuthorizerAppid        string `json:"authorizer_appid"`
+	AuthorizerAccessToken  string `json:"authorizer_access_token"`
+	AuthorizerRefreshToken string `json:"authorizer_refresh_token"`
+	ExpiresIn              int64  `json:"expires_in"` // 当前时间 + 过期时间
+}
+
+// 授权方token存储, 未找到时返回 ErrAuthorizerTokenNotFound
+type AuthorizerTokenServer interface {
+	SetAuthorizerToken(token *AuthorizerToken) error
+	GetAuthorizerToken(authorizerAppid string) (*AuthorizerToken, error)
+}
+
+type defaultAuthorizerTokenServer struct {
+	sync.RWMutex
+	tokens map[string]AuthorizerToken
+}
+
+var _ AuthorizerTokenServer = (*defaultAuthorizerTokenServer)(nil)
+
+func newDefaultAuthorizerTokenServer() *defaultAuthorizerTokenServer {
+	return &defaultAuthorizerTokenServer{tokens: make(map[string]AuthorizerToken)}
+}
+
+func (d *defaultAuthorizerTokenServer) SetAuthorizerToken(token *AuthorizerToken) error {
+	d.Lock()
+	defer d.Unlock()
+	d.tokens[token.AuthorizerAppid] = *token
+	return nil
+}
+
+func (d *defaultAuthorizerTokenServer) GetAuthorizerToken(authorizerAppid string) (*AuthorizerToken, error) {
+	d.RLock()
+	defer d.RUnlock()
+	token, ok := d.tokens[authorizerAppid]
+	if !ok {
+		return nil, ErrAuthorizerTokenNotFound
+	}
+	return &token, nil
+}
+
+// 设置授权方token存储, 默认保存在内存中
+func (srv *Server) SetAuthorizerTokenServer(s AuthorizerTokenServer) {
+	srv.authorizerTokenServer = s
+}
+
+// 获取授权方的access_token, 过期时使用refresh_token刷新并保存
+func (srv *Server) AuthorizerToken(authorizerAppid string) (string, error) {
+	token, err := srv.authorizerTokenServer.GetAuthorizerToken(authorizerAppid)
+	if err != nil {
+		return "", err
+	}
+	if token.ExpiresIn > time.Now().Unix() {
+		return token.AuthorizerAccessToken, nil
+	}
+	// 刷新时加锁并重新读取, 避免并发刷新使用已失效的refresh_token
+	srv.authorizerTokenMu.Lock()
+	defer srv.authorizerTokenMu.Unlock()
+	token, err = srv.authorizerTokenServer.GetAuthorizerToken(authorizerAppid)
+	if err != nil {
+		return "", err
+	}
+	timeUnix := time.Now().Unix()
+	if token.ExpiresIn > timeUnix {
+		return token.AuthorizerAccessToken, nil
+	}
+	resp, err := srv.RefreshToken(authorizerAppid, token.AuthorizerRefreshToken)
+	if err != nil {
+		return "", err
+	}
+	if err = resp.Err(); err != nil {
+		return "", err
+	}
+	token = &AuthorizerToken{
+		AuthorizerAppid:        authorizerAppid,
+		AuthorizerAccessToken:  resp.AuthorizerAccessToken,
+		AuthorizerRefreshToken: resp.AuthorizerRefreshToken,
+		ExpiresIn:              timeUnix + resp.ExpiresIn,
+	}
+	if err = srv.authorizerTokenServer.SetAuthorizerToken(token); err != nil {
+		return "", err
+	}
+	return token.AuthorizerAccessToken, nil
+}
+
+type RecoverReason string
+
+// 需要恢复refresh_token的原因
+const (
+	RecoverReasonMissing RecoverReason = "missing" // 存储中没有该授权方
+	RecoverReasonStale   RecoverReason = "stale"   // 存储的refresh_token与授权列表中的不一致
+)
+
+type RecoveredAuthorizer struct {
+	AuthorizerAppid string
+	Reason          RecoverReason
+}
+
+// 从授权列表恢复丢失或过期的refresh_token, 返回修复的授权方
+// 恢复后access_token会在下次调用 AuthorizerToken 时刷新
+func (srv *Server) RecoverRefreshTokens() ([]RecoveredAuthorizer, error) {
+	var recovered []RecoveredAuthorizer
+	var storeErr error
+	err := srv.RangeAuthorizers(authorizerListMaxCount, 0, func(item AuthorizerListItem) bool {
+		// 与 AuthorizerToken 的刷新互斥, 避免覆盖刚刷新的refresh_token
+		srv.authorizerTokenMu.Lock()
+		defer srv.authorizerTokenMu.Unlock()
+		var reason RecoverReason
+		token, err := srv.authorizerTokenServer.GetAuthorizerToken(item.AuthorizerAppid)
+		switch {
+		case err == ErrAuthorizerTokenNotFound:
+			reason = RecoverReasonMissing
+		case err != nil:
+			storeErr = err
+			return false
+		case token.AuthorizerRefreshToken != item.RefreshToken:
+			reason = RecoverReasonStale
+		default:
+			return true
+		}
+		storeErr = srv.authorizerTokenServer.SetAuthorizerToken(&AuthorizerToken{
+			AuthorizerAppid:        item.AuthorizerAppid,
+			AuthorizerRefreshToken: item.RefreshToken,
+		})
+		if storeErr != nil {
+			return false
+		}
+		recovered = append(recovered, RecoveredAuthorizer{AuthorizerAppid: item.AuthorizerAppid, Reason: reason})
+		return true
+	})
+	if err != nil {
+		return recovered, err
+	}
+	return recovered, storeErr
+}
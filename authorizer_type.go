@@ -0,0 +1,117 @@
+package open_wechat
+
+// service_type_info.id, 公众号和小程序的取值含义不同
+type ServiceType int
+
+// 公众号类型
+const (
+	ServiceTypeSubscription         ServiceType = 0 // 订阅号
+	ServiceTypeUpgradedSubscription ServiceType = 1 // 由历史老帐号升级后的订阅号
+	ServiceTypeService              ServiceType = 2 // 服务号
+)
+
+// 小程序类型
+const (
+	ServiceTypeMiniProgram     ServiceType = 0  // 普通小程序
+	ServiceTypeMiniGame        ServiceType = 4  // 小游戏
+	ServiceTypeMiniShop        ServiceType = 10 // 小商店
+	ServiceTypeBetaMiniProgram ServiceType = 12 // 试用小程序
+)
+
+// verify_type_info.id
+type VerifyType int
+
+// 认证类型
+const (
+	VerifyTypeNone                      VerifyType = -1 // 未认证
+	VerifyTypeWechat                    VerifyType = 0  // 微信认证
+	VerifyTypeSinaWeibo                 VerifyType = 1  // 新浪微博认证
+	VerifyTypeTencentWeibo              VerifyType = 2  // 腾讯微博认证
+	VerifyTypeQualification             VerifyType = 3  // 已资质认证通过但还未通过名称认证
+	VerifyTypeQualificationSinaWeibo    VerifyType = 4  // 已资质认证通过, 还未通过名称认证, 但通过了新浪微博认证
+	VerifyTypeQualificationTencentWeibo VerifyType = 5  // 已资质认证通过, 还未通过名称认证, 但通过了腾讯微博认证
+)
+
+// 是否通过了认证
+func (t VerifyType) Verified() bool {
+	return t != VerifyTypeNone
+}
+
+// 是否小程序, 以是否返回MiniProgramInfo判断
+func (info *AuthorizerInfo) IsMiniProgram() bool {
+	return info.Miniprograminfo != nil
+}
+
+// 是否公众号
+func (info *AuthorizerInfo) IsOfficialAccount() bool {
+	return info.Miniprograminfo == nil
+}
+
+func (info *AuthorizerInfo) Type() AuthorizerType {
+	if info.IsMiniProgram() {
+		return AuthorizerTypeMiniProgram
+	}
+	return AuthorizerTypeOfficialAccount
+}
+
+// 公众号详情
+type OfficialAccountInfo struct {
+	NickName      string
+	HeadImg       string
+	ServiceType   ServiceType
+	VerifyType    VerifyType
+	UserName      string
+	PrincipalName string
+	BusinessInfo  BusinessInfo
+	Alias         string
+	QrcodeURL     string
+}
+
+// 小程序详情
+type MiniProgramAccountInfo struct {
+	NickName        string
+	HeadImg         string
+	ServiceType     ServiceType
+	VerifyType      VerifyType
+	UserName        string
+	PrincipalName   string
+	Signature       string
+	QrcodeURL       string
+	MiniProgramInfo MiniProgramInfo
+}
+
+// 公众号详情, 不是公众号时返回false
+func (info *AuthorizerInfo) OfficialAccount() (*OfficialAccountInfo, bool) {
+	if !info.IsOfficialAccount() {
+		return nil, false
+	}
+	return &OfficialAccountInfo{
+		NickName:      info.NickName,
+		HeadImg:       info.HeadImg,
+		ServiceType:   info.ServiceTypeInfo.ID,
+		VerifyType:    info.VerifyTypeInfo.ID,
+		UserName:      info.UserName,
+		PrincipalName: info.PrincipalName,
+		BusinessInfo:  info.BusinessInfo,
+		Alias:         info.Alias,
+		QrcodeURL:     info.QrcodeURL,
+	}, true
+}
+
+// 小程序详情, 不是小程序时返回false
+func (info *AuthorizerInfo) MiniProgram() (*MiniProgramAccountInfo, bool) {
+	if !info.IsMiniProgram() {
+		return nil, false
+	}
+	return &MiniProgramAccountInfo{
+		NickName:        info.NickName,
+		HeadImg:         info.HeadImg,
+		ServiceType:     info.ServiceTypeInfo.ID,
+		VerifyType:      info.VerifyTypeInfo.ID,
+		UserName:        info.UserName,
+		PrincipalName:   info.PrincipalName,
+		Signature:       info.Signature,
+		QrcodeURL:       info.QrcodeURL,
+		MiniProgramInfo: *info.Miniprograminfo,
+	}, true
+}
@@ -0,0 +1,107 @@
+package open_wechat
+
+import "github.com/owen-gxz/open-wechat/core"
+
+// 试用小程序
+const (
+	FastRegisterBetaWeappUrl = wechatApiUrl + "/wxa/component/fastregisterbetaweapp?access_token=%s"
+	VerifyBetaWeappUrl       = wechatApiUrl + "/wxa/verifybetaweapp?access_token=%s"
+
+	// InfoTypeNotifyThirdFastRegisterBeta 创建试用小程序结果
+	InfoTypeNotifyThirdFastRegisterBeta = "notify_third_fastregisterbetaapp"
+	// InfoTypeNotifyThirdFastVerifyBeta 试用小程序转正结果
+	InfoTypeNotifyThirdFastVerifyBeta = "notify_third_fastverifybetaapp"
+)
+
+type FastRegisterBetaWeappRequest struct {
+	// 小程序名称
+	Name string `json:"name"`
+	// 创建者在第三方平台公众号下的openid
+	Openid string `json:"openid"`
+}
+
+type FastRegisterBetaWeappResponse struct {
+	core.Error
+	// 用户在微信中打开该链接确认创建
+	AuthorizeUrl string `json:"authorize_url"`
+	// 与推送中的unique_id对应
+	UniqueId string `json:"unique_id"`
+}
+
+// 创建试用小程序, 结果通过 InfoTypeNotifyThirdFastRegisterBeta 推送, 见 OnFastRegisterBeta
+func (srv *Server) FastRegisterBetaWeapp(name, openid string) (*FastRegisterBetaWeappResponse, error) {
+	accessToken, err := srv.Token()
+	if err != nil {
+		return nil, err
+	}
+	req := FastRegisterBetaWeappRequest{
+		Name:   name,
+		Openid: openid,
+	}
+	resp := &FastRegisterBetaWeappResponse{}
+	err = srv.PostJson(getCompleteUrl(FastRegisterBetaWeappUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type VerifyBetaWeappInfo struct {
+	EnterpriseName     string   `json:"enterprise_name"`
+	Code               string   `json:"code"`
+	CodeType           CodeType `json:"code_type"`
+	LegalPersonaWechat string   `json:"legal_persona_wechat"`
+	LegalPersonaName   string   `json:"legal_persona_name"`
+	// 法人身份证号
+	LegalPersonaIdcard string `json:"legal_persona_idcard"`
+	ComponentPhone     string `json:"component_phone,omitempty"`
+}
+
+type VerifyBetaWeappRequest struct {
+	VerifyInfo VerifyBetaWeappInfo `json:"verify_info"`
+}
+
+type VerifyBetaWeappResponse struct {
+	core.Error
+}
+
+// 试用小程序转为正式小程序, 结果通过 InfoTypeNotifyThirdFastVerifyBeta 推送, 见 OnVerifyBeta
+func (srv *Server) VerifyBetaWeapp(authorizerAppid string, info VerifyBetaWeappInfo) (*VerifyBetaWeappResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaVerify)
+	if err != nil {
+		return nil, err
+	}
+	req := VerifyBetaWeappRequest{
+		VerifyInfo: info,
+	}
+	resp := &VerifyBetaWeappResponse{}
+	err = srv.PostJson(getCompleteUrl(VerifyBetaWeappUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// 处理创建试用小程序结果推送, Info中带有unique_id和name, 先返回success再在goroutine中调用fn
+func (srv *Server) OnFastRegisterBeta(fn func(result *FastRegisterResult)) {
+	srv.AddHander(InfoTypeNotifyThirdFastRegisterBeta, srv.fastRegisterHandler(fn))
+}
+
+// 处理试用小程序转正结果推送, 先返回success再在goroutine中调用fn
+func (srv *Server) OnVerifyBeta(fn func(result *FastRegisterResult)) {
+	srv.AddHander(InfoTypeNotifyThirdFastVerifyBeta, srv.fastRegisterHandler(fn))
+}
+
+func (srv *Server) fastRegisterHandler(fn func(result *FastRegisterResult)) HandlerChain {
+	return func(c Context) {
+		msg := c.MixedMsg
+		result := &FastRegisterResult{
+			Appid:  msg.RegisterAppid,
+			Status: msg.RegisterStatus,
+			Msg:    msg.RegisterMsg,
+			Info:   msg.RegisterInfo,
+		}
+		c.w.Write(Success)
+		go fn(result)
+	}
+}
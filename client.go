@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"github.com/owen-gxz/open-wechat/core"
+	"io/ioutil"
 	"net/http"
+	"strings"
 )
 
 type Client struct {
@@ -37,3 +40,45 @@ func (cli *Client) PostJson(incompleteURL string, request interface{}, response
 	}
 	return json.NewDecoder(httpResp.Body).Decode(&response)
 }
+
+// get 请求, 返回json
+func (cli *Client) GetJson(incompleteURL string, response interface{}) error {
+	httpResp, err := cli.client.Get(incompleteURL)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("http.Status: %s", httpResp.Status)
+	}
+	return json.NewDecoder(httpResp.Body).Decode(&response)
+}
+
+// get 请求, 返回图片等二进制数据, 微信返回json时解析为错误
+func (cli *Client) GetBytes(incompleteURL string) ([]byte, error) {
+	httpResp, err := cli.client.Get(incompleteURL)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http.Status: %s", httpResp.Status)
+	}
+	body, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	contentType := httpResp.Header.Get("Content-Type")
+	if strings.HasPrefix(contentType, "application/json") || strings.HasPrefix(contentType, "text/plain") {
+		wxErr := &core.Error{}
+		if err = json.Unmarshal(body, wxErr); err != nil {
+			return nil, err
+		}
+		if err = wxErr.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return body, nil
+}
@@ -0,0 +1,208 @@
+package open_wechat
+
+import (
+	"fmt"
+	"github.com/owen-gxz/open-wechat/core"
+	"strings"
+)
+
+// 第三方平台的小程序服务器域名和业务域名, 使用第三方平台token
+const (
+	ComponentServerDomainUrl      = wechatApiUrl + "/cgi-bin/component/modify_wxa_server_domain?access_token=%s"
+	ComponentDomainConfirmFileUrl = wechatApiUrl + "/cgi-bin/component/get_domain_confirmfile?access_token=%s"
+	ComponentJumpDomainUrl        = wechatApiUrl + "/cgi-bin/component/modify_wxa_jump_domain?access_token=%s"
+
+	componentDomainSeparator = ";"
+)
+
+type ComponentServerDomainRequest struct {
+	Action DomainAction `json:"action"`
+	// 多个域名用;分隔
+	WxaServerDomain string `json:"wxa_server_domain,omitempty"`
+	// 是否同时修改全网发布版本的域名
+	IsModifyPublishedTogether bool `json:"is_modify_published_together"`
+}
+
+type ComponentServerDomainResponse struct {
+	core.Error
+	// 全网发布版本的域名
+	PublishedWxaServerDomain string `json:"published_wxa_server_domain"`
+	// 测试版本的域名
+	TestingWxaServerDomain string `json:"testing_wxa_server_domain"`
+	// 未通过验证的域名
+	InvalidWxaServerDomain string `json:"invalid_wxa_server_domain"`
+}
+
+// 设置第三方平台的小程序服务器域名, 会推送到授权的小程序
+func (srv *Server) ComponentServerDomain(action DomainAction, domains []string, isModifyPublishedTogether bool) (*ComponentServerDomainResponse, error) {
+	accessToken, err := srv.Token()
+	if err != nil {
+		return nil, err
+	}
+	req := ComponentServerDomainRequest{
+		Action:                    action,
+		WxaServerDomain:           strings.Join(domains, componentDomainSeparator),
+		IsModifyPublishedTogether: isModifyPublishedTogether,
+	}
+	resp := &ComponentServerDomainResponse{}
+	err = srv.PostJson(getCompleteUrl(ComponentServerDomainUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// 获取第三方平台业务域名校验文件
+func (srv *Server) ComponentDomainConfirmFile() (*DomainConfirmFileResponse, error) {
+	accessToken, err := srv.Token()
+	if err != nil {
+		return nil, err
+	}
+	resp := &DomainConfirmFileResponse{}
+	err = srv.PostJson(getCompleteUrl(ComponentDomainConfirmFileUrl, accessToken), core.H{}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type ComponentJumpDomainRequest struct {
+	Action DomainAction `json:"action"`
+	// 多个域名用;分隔
+	WxaJumpH5Domain           string `json:"wxa_jump_h5_domain,omitempty"`
+	IsModifyPublishedTogether bool   `json:"is_modify_published_together"`
+}
+
+type ComponentJumpDomainResponse struct {
+	core.Error
+	PublishedWxaJumpH5Domain string `json:"published_wxa_jump_h5_domain"`
+	TestingWxaJumpH5Domain   string `json:"testing_wxa_jump_h5_domain"`
+	InvalidWxaJumpH5Domain   string `json:"invalid_wxa_jump_h5_domain"`
+}
+
+// 设置第三方平台的小程序业务域名, 需要先将 ComponentDomainConfirmFile 返回的校验文件放到域名根目录
+func (srv *Server) ComponentJumpDomain(action DomainAction, domains []string, isModifyPublishedTogether bool) (*ComponentJumpDomainResponse, error) {
+	accessToken, err := srv.Token()
+	if err != nil {
+		return nil, err
+	}
+	req := ComponentJumpDomainRequest{
+		Action:                    action,
+		WxaJumpH5Domain:           strings.Join(domains, componentDomainSeparator),
+		IsModifyPublishedTogether: isModifyPublishedTogether,
+	}
+	resp := &ComponentJumpDomainResponse{}
+	err = srv.PostJson(getCompleteUrl(ComponentJumpDomainUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// 同步的结果
+type DomainSyncResult struct {
+	// 添加成功的域名
+	Added   []string
+	Deleted []string
+	// 微信未通过验证的域名
+	Invalid []string
+}
+
+// 将第三方平台的服务器域名同步为desired, 只添加缺少的和删除多余的
+// published为true时以全网发布版本为准并同时修改, 否则以测试版本为准
+func (srv *Server) SyncComponentServerDomain(desired []string, published bool) (*DomainSyncResult, error) {
+	return syncDomains(desired, func(action DomainAction, domains []string) ([]string, []string, error) {
+		resp, err := srv.ComponentServerDomain(action, domains, published)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err = resp.Err(); err != nil {
+			return nil, nil, err
+		}
+		invalid := splitDomains(resp.InvalidWxaServerDomain)
+		if published {
+			return splitDomains(resp.PublishedWxaServerDomain), invalid, nil
+		}
+		return splitDomains(resp.TestingWxaServerDomain), invalid, nil
+	})
+}
+
+// 将第三方平台的业务域名同步为desired
+func (srv *Server) SyncComponentJumpDomain(desired []string, published bool) (*DomainSyncResult, error) {
+	return syncDomains(desired, func(action DomainAction, domains []string) ([]string, []string, error) {
+		resp, err := srv.ComponentJumpDomain(action, domains, published)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err = resp.Err(); err != nil {
+			return nil, nil, err
+		}
+		invalid := splitDomains(resp.InvalidWxaJumpH5Domain)
+		if published {
+			return splitDomains(resp.PublishedWxaJumpH5Domain), invalid, nil
+		}
+		return splitDomains(resp.TestingWxaJumpH5Domain), invalid, nil
+	})
+}
+
+// modify执行域名操作并返回操作后的域名和未通过验证的域名
+// 操作后的域名与desired不一致时返回result和错误, 未生效的域名不计入Added和Deleted
+func syncDomains(desired []string, modify func(action DomainAction, domains []string) ([]string, []string, error)) (*DomainSyncResult, error) {
+	actual, _, err := modify(DomainActionGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	toAdd := diffDomains(desired, actual)
+	toDelete := diffDomains(actual, desired)
+	result := &DomainSyncResult{}
+	if len(toAdd) > 0 {
+		var invalid []string
+		if actual, invalid, err = modify(DomainActionAdd, toAdd); err != nil {
+			return nil, err
+		}
+		result.Invalid = invalid
+		result.Added = intersectDomains(toAdd, actual)
+	}
+	if len(toDelete) > 0 {
+		if actual, _, err = modify(DomainActionDelete, toDelete); err != nil {
+			return result, err
+		}
+		result.Deleted = diffDomains(toDelete, actual)
+	}
+	missing := diffDomains(desired, actual)
+	extra := diffDomains(actual, desired)
+	if len(missing) > 0 || len(extra) > 0 {
+		return result, fmt.Errorf("domains not synced, missing: %v, extra: %v, invalid: %v", missing, extra, result.Invalid)
+	}
+	return result, nil
+}
+
+func splitDomains(s string) []string {
+	var domains []string
+	for _, domain := range strings.Split(s, componentDomainSeparator) {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+// 在a中不在b中的域名
+func diffDomains(a, b []string) []string {
+	exists := make(map[string]bool, len(b))
+	for _, domain := range b {
+		exists[domain] = true
+	}
+	var diff []string
+	for _, domain := range a {
+		if !exists[domain] {
+			diff = append(diff, domain)
+		}
+	}
+	return diff
+}
+
+// 在a中也在b中的域名
+func intersectDomains(a, b []string) []string {
+	return diffDomains(a, diffDomains(a, b))
+}
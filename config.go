@@ -0,0 +1,103 @@
+package open_wechat
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Config在环境变量和配置文件中对应的key, 环境变量会加上前缀, 如: OPEN_WECHAT_APP_ID
+const (
+	ConfigKeyAppID      = "APP_ID"
+	ConfigKeyAppSecret  = "APP_SECRET"
+	ConfigKeyAESKey     = "AES_KEY"
+	ConfigKeyToken      = "TOKEN"
+	ConfigKeyPrevAESKey = "PREV_AES_KEY"
+	ConfigKeyPrevToken  = "PREV_TOKEN"
+
+	DefaultConfigEnvPrefix = "OPEN_WECHAT_"
+)
+
+// 校验配置, 所有字段都必须设置, Prev开头的字段可以为空
+func (cfg Config) Validate() error {
+	if cfg.AppID == "" {
+		return errors.New("config AppID is empty")
+	}
+	if cfg.AppSecret == "" {
+		return errors.New("config AppSecret is empty")
+	}
+	if cfg.Token == "" {
+		return errors.New("config Token is empty")
+	}
+	if cfg.AESKey == "" {
+		return errors.New("config AESKey is empty")
+	}
+	if _, err := decodeAESKey(cfg.AESKey); err != nil {
+		return fmt.Errorf("config AESKey: %s", err)
+	}
+	if cfg.PrevAESKey != "" {
+		if _, err := decodeAESKey(cfg.PrevAESKey); err != nil {
+			return fmt.Errorf("config PrevAESKey: %s", err)
+		}
+	}
+	return nil
+}
+
+// 从环境变量加载配置, prefix为空时使用 DefaultConfigEnvPrefix
+func LoadConfigFromEnv(prefix string) (Config, error) {
+	if prefix == "" {
+		prefix = DefaultConfigEnvPrefix
+	}
+	values := make(map[string]string)
+	for _, key := range []string{ConfigKeyAppID, ConfigKeyAppSecret, ConfigKeyAESKey, ConfigKeyToken, ConfigKeyPrevAESKey, ConfigKeyPrevToken} {
+		values[key] = os.Getenv(prefix + key)
+	}
+	cfg := configFromValues(values)
+	return cfg, cfg.Validate()
+}
+
+// 从key=value格式的文件加载配置, #开头的行为注释, key不区分大小写, 如:
+//
+//	app_id=wx1234567890
+//	app_secret=xxx
+func LoadConfigFromFile(path string) (Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Config{}, err
+	}
+	defer f.Close()
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		i := strings.Index(text, "=")
+		if i < 0 {
+			return Config{}, fmt.Errorf("%s:%d: missing '='", path, line)
+		}
+		key := strings.ToUpper(strings.TrimSpace(text[:i]))
+		values[key] = strings.TrimSpace(text[i+1:])
+	}
+	if err = scanner.Err(); err != nil {
+		return Config{}, err
+	}
+	cfg := configFromValues(values)
+	return cfg, cfg.Validate()
+}
+
+func configFromValues(values map[string]string) Config {
+	return Config{
+		AppID:      values[ConfigKeyAppID],
+		AppSecret:  values[ConfigKeyAppSecret],
+		AESKey:     values[ConfigKeyAESKey],
+		Token:      values[ConfigKeyToken],
+		PrevAESKey: values[ConfigKeyPrevAESKey],
+		PrevToken:  values[ConfigKeyPrevToken],
+	}
+}
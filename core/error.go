@@ -2,11 +2,14 @@ package core
 
 import (
 	"fmt"
+	"strings"
 )
 
 const (
 	errorErrCodeIndex = 0
 	errorErrMsgIndex  = 1
+
+	ridPrefix = "rid: "
 )
 
 type Error struct {
@@ -18,4 +21,21 @@ func (err *Error) Error() string {
 	return fmt.Sprintf("errcode: %d, errmsg: %s", err.ErrCode, err.ErrMsg)
 }
 
+// Err errcode为0时返回nil, 否则返回自身
+func (err *Error) Err() error {
+	if err.ErrCode == 0 {
+		return nil
+	}
+	return err
+}
+
+// Rid 调用失败时微信在errmsg末尾返回的请求id, 可用于rid/get查询请求详情
+func (err *Error) Rid() string {
+	i := strings.LastIndex(err.ErrMsg, ridPrefix)
+	if i < 0 {
+		return ""
+	}
+	return strings.TrimSpace(err.ErrMsg[i+len(ridPrefix):])
+}
+
 type H map[string]interface{}
@@ -0,0 +1,120 @@
+package open_wechat
+
+import "github.com/owen-gxz/open-wechat/core"
+
+// 快速创建企业小程序
+const (
+	FastRegisterWeappUrl       = wechatApiUrl + "/cgi-bin/component/fastregisterweapp?action=create&component_access_token=%s"
+	SearchFastRegisterWeappUrl = wechatApiUrl + "/cgi-bin/component/fastregisterweapp?action=search&component_access_token=%s"
+
+	// InfoTypeNotifyThirdFastRegister 快速创建小程序结果
+	InfoTypeNotifyThirdFastRegister = "notify_third_fasteregister"
+)
+
+type CodeType int
+
+// 企业代码类型
+const (
+	CodeTypeCreditCode         CodeType = 1 // 统一社会信用代码
+	CodeTypeOrganizationCode   CodeType = 2 // 组织机构代码
+	CodeTypeBusinessLicenseNum CodeType = 3 // 营业执照注册号
+)
+
+// 企业信息, 推送中的info也是该结构
+type FastRegisterInfo struct {
+	// 企业名称
+	Name string `json:"name" xml:"name"`
+	// 企业代码
+	Code     string   `json:"code" xml:"code"`
+	CodeType CodeType `json:"code_type" xml:"code_type"`
+	// 法人微信号
+	LegalPersonaWechat string `json:"legal_persona_wechat" xml:"legal_persona_wechat"`
+	// 法人姓名
+	LegalPersonaName string `json:"legal_persona_name" xml:"legal_persona_name"`
+	// 第三方联系电话
+	ComponentPhone string `json:"component_phone,omitempty" xml:"component_phone"`
+	// 试用小程序的任务id, 仅在试用小程序推送中返回
+	UniqueId string `json:"unique_id,omitempty" xml:"unique_id"`
+}
+
+type FastRegisterWeappResponse struct {
+	core.Error
+}
+
+// 快速创建小程序, 结果通过 InfoTypeNotifyThirdFastRegister 推送, 见 OnFastRegister
+func (srv *Server) FastRegisterWeapp(info *FastRegisterInfo) (*FastRegisterWeappResponse, error) {
+	accessToken, err := srv.Token()
+	if err != nil {
+		return nil, err
+	}
+	resp := &FastRegisterWeappResponse{}
+	err = srv.PostJson(getCompleteUrl(FastRegisterWeappUrl, accessToken), info, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type SearchFastRegisterWeappRequest struct {
+	Name               string `json:"name"`
+	LegalPersonaWechat string `json:"legal_persona_wechat"`
+	LegalPersonaName   string `json:"legal_persona_name"`
+}
+
+// 查询创建任务状态, 结果同样通过推送返回
+func (srv *Server) SearchFastRegisterWeapp(name, legalPersonaWechat, legalPersonaName string) (*FastRegisterWeappResponse, error) {
+	accessToken, err := srv.Token()
+	if err != nil {
+		return nil, err
+	}
+	req := SearchFastRegisterWeappRequest{
+		Name:               name,
+		LegalPersonaWechat: legalPersonaWechat,
+		LegalPersonaName:   legalPersonaName,
+	}
+	resp := &FastRegisterWeappResponse{}
+	err = srv.PostJson(getCompleteUrl(SearchFastRegisterWeappUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// 快速创建小程序结果
+type FastRegisterResult struct {
+	// 创建的小程序appid
+	Appid string
+	// 0为成功
+	Status   int
+	Msg      string
+	AuthCode string
+	Info     *FastRegisterInfo
+	// 创建成功时使用AuthCode调用QueryAuth的结果
+	QueryAuth    *QueryAuthResponse
+	QueryAuthErr error
+}
+
+// 处理快速创建小程序结果推送, 推送需要在5秒内返回, 收到后先返回success,
+// 再在goroutine中调用QueryAuth(创建成功时)和fn
+func (srv *Server) OnFastRegister(fn func(result *FastRegisterResult)) {
+	srv.AddHander(InfoTypeNotifyThirdFastRegister, func(c Context) {
+		msg := c.MixedMsg
+		result := &FastRegisterResult{
+			Appid:    msg.RegisterAppid,
+			Status:   msg.RegisterStatus,
+			Msg:      msg.RegisterMsg,
+			AuthCode: msg.AuthCode,
+			Info:     msg.RegisterInfo,
+		}
+		c.w.Write(Success)
+		go func() {
+			if result.Status == 0 && result.AuthCode != "" {
+				result.QueryAuth, result.QueryAuthErr = srv.QueryAuth(result.AuthCode)
+				if result.QueryAuthErr == nil {
+					result.QueryAuthErr = result.QueryAuth.Err()
+				}
+			}
+			fn(result)
+		}()
+	})
+}
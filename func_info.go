@@ -0,0 +1,167 @@
+package open_wechat
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// 权限集id, 即func_info中的funcscope_category.id
+type FuncScope int
+
+// 公众号权限集
+const (
+	FuncScopeMessage           FuncScope = 1  // 消息管理
+	FuncScopeUser              FuncScope = 2  // 用户管理
+	FuncScopeAccountService    FuncScope = 3  // 帐号服务
+	FuncScopeWebService        FuncScope = 4  // 网页服务
+	FuncScopeStore             FuncScope = 5  // 微信小店
+	FuncScopeCustomerService   FuncScope = 6  // 微信多客服
+	FuncScopeMassSend          FuncScope = 7  // 群发与通知
+	FuncScopeCard              FuncScope = 8  // 微信卡券
+	FuncScopeScan              FuncScope = 9  // 微信扫一扫
+	FuncScopeWifi              FuncScope = 10 // 微信连WIFI
+	FuncScopeMaterial          FuncScope = 11 // 素材管理
+	FuncScopeShakeAround       FuncScope = 12 // 微信摇周边
+	FuncScopePoi               FuncScope = 13 // 微信门店
+	FuncScopeMenu              FuncScope = 15 // 自定义菜单
+	FuncScopeCityService       FuncScope = 22 // 城市服务
+	FuncScopeAd                FuncScope = 23 // 广告管理
+	FuncScopeOpenAccount       FuncScope = 24 // 开放平台帐号管理
+	FuncScopeInvoice           FuncScope = 26 // 微信电子发票
+	FuncScopeFastRegisterWeapp FuncScope = 27 // 快速注册小程序
+	FuncScopeWeappManage       FuncScope = 33 // 小程序管理
+	FuncScopeProduct           FuncScope = 34 // 微信商品库
+	FuncScopeServiceMarket     FuncScope = 66 // 服务平台管理
+)
+
+// 小程序权限集
+const (
+	FuncScopeWxaAccount         FuncScope = 17 // 帐号管理
+	FuncScopeWxaDevelop         FuncScope = 18 // 开发管理与数据分析
+	FuncScopeWxaCustomerService FuncScope = 19 // 客服消息管理
+	FuncScopeWxaOpenAccount     FuncScope = 25 // 开放平台帐号管理
+	FuncScopeWxaBasicInfo       FuncScope = 30 // 小程序基本信息设置
+	FuncScopeWxaVerify          FuncScope = 31 // 小程序认证
+	FuncScopeWxaNearby          FuncScope = 37 // 附近地点
+	FuncScopeWxaPlugin          FuncScope = 40 // 插件管理
+	FuncScopeWxaLogistics       FuncScope = 45 // 微信物流服务
+	FuncScopeWxaCloud           FuncScope = 49 // 云开发管理
+	FuncScopeWxaLive            FuncScope = 52 // 小程序直播
+)
+
+var FuncScopeNames = map[FuncScope]string{
+	FuncScopeMessage:            "消息管理",
+	FuncScopeUser:               "用户管理",
+	FuncScopeAccountService:     "帐号服务",
+	FuncScopeWebService:         "网页服务",
+	FuncScopeStore:              "微信小店",
+	FuncScopeCustomerService:    "微信多客服",
+	FuncScopeMassSend:           "群发与通知",
+	FuncScopeCard:               "微信卡券",
+	FuncScopeScan:               "微信扫一扫",
+	FuncScopeWifi:               "微信连WIFI",
+	FuncScopeMaterial:           "素材管理",
+	FuncScopeShakeAround:        "微信摇周边",
+	FuncScopePoi:                "微信门店",
+	FuncScopeMenu:               "自定义菜单",
+	FuncScopeCityService:        "城市服务",
+	FuncScopeAd:                 "广告管理",
+	FuncScopeOpenAccount:        "开放平台帐号管理",
+	FuncScopeInvoice:            "微信电子发票",
+	FuncScopeFastRegisterWeapp:  "快速注册小程序",
+	FuncScopeWeappManage:        "小程序管理",
+	FuncScopeProduct:            "微信商品库",
+	FuncScopeServiceMarket:      "服务平台管理",
+	FuncScopeWxaAccount:         "帐号管理",
+	FuncScopeWxaDevelop:         "开发管理与数据分析",
+	FuncScopeWxaCustomerService: "客服消息管理",
+	FuncScopeWxaOpenAccount:     "开放平台帐号管理",
+	FuncScopeWxaBasicInfo:       "小程序基本信息设置",
+	FuncScopeWxaVerify:          "小程序认证",
+	FuncScopeWxaNearby:          "附近地点",
+	FuncScopeWxaPlugin:          "插件管理",
+	FuncScopeWxaLogistics:       "微信物流服务",
+	FuncScopeWxaCloud:           "云开发管理",
+	FuncScopeWxaLive:            "小程序直播",
+}
+
+func (scope FuncScope) String() string {
+	if name, ok := FuncScopeNames[scope]; ok {
+		return name
+	}
+	return strconv.Itoa(int(scope))
+}
+
+type FuncInfo struct {
+	FuncscopeCategory struct {
+		ID FuncScope `json:"id"`
+	} `json:"funcscope_category"`
+}
+
+type FuncInfoList []FuncInfo
+
+// 授权的权限集id
+func (l FuncInfoList) Scopes() []FuncScope {
+	scopes := make([]FuncScope, len(l))
+	for i, info := range l {
+		scopes[i] = info.FuncscopeCategory.ID
+	}
+	return scopes
+}
+
+// 是否授权了其中任意一个权限集
+func (l FuncInfoList) Has(scopes ...FuncScope) bool {
+	for _, info := range l {
+		for _, scope := range scopes {
+			if info.FuncscopeCategory.ID == scope {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 授权方没有授权需要的权限集
+type PermissionError struct {
+	AuthorizerAppid string
+	Scopes          []FuncScope
+}
+
+func (err *PermissionError) Error() string {
+	return fmt.Sprintf("authorizer %s did not grant func scope %v", err.AuthorizerAppid, err.Scopes)
+}
+
+// 授权方是否授权了权限集, 设置了 AuthorizerInfoCache 时使用缓存
+func (srv *Server) HasPermission(authorizerAppid string, scope FuncScope) (bool, error) {
+	info, err := srv.CachedAuthorizerInfo(authorizerAppid)
+	if err != nil {
+		return false, err
+	}
+	if err = info.Err(); err != nil {
+		return false, err
+	}
+	return info.AuthorizationInfo.FuncInfo.Has(scope), nil
+}
+
+// 开启后代授权方调用接口前会检查是否授权了对应的权限集, 没有授权时返回 PermissionError
+// 每次检查会获取授权方详情, 建议同时设置 AuthorizerInfoCache
+func (srv *Server) SetPermissionGuard(enable bool) {
+	srv.permissionGuard = enable
+}
+
+// 获取授权方token, 开启权限检查时授权方需要授权scopes中的任意一个
+func (srv *Server) authorizerTokenFor(authorizerAppid string, scopes ...FuncScope) (string, error) {
+	if srv.permissionGuard && len(scopes) > 0 {
+		info, err := srv.CachedAuthorizerInfo(authorizerAppid)
+		if err != nil {
+			return "", err
+		}
+		if err = info.Err(); err != nil {
+			return "", err
+		}
+		if !info.AuthorizationInfo.FuncInfo.Has(scopes...) {
+			return "", &PermissionError{AuthorizerAppid: authorizerAppid, Scopes: scopes}
+		}
+	}
+	return srv.AuthorizerToken(authorizerAppid)
+}
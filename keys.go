@@ -0,0 +1,121 @@
+package open_wechat
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/owen-gxz/open-wechat/util"
+)
+
+// 回调使用的Token和AESKey, 修改期间同时保留当前值和旧值
+type callbackKeys struct {
+	token      string
+	prevToken  string
+	aesKey     []byte
+	prevAESKey []byte
+}
+
+func decodeAESKey(base64AESKey string) ([]byte, error) {
+	if len(base64AESKey) != 43 {
+		return nil, errors.New("the length of base64AESKey must equal to 43")
+	}
+	aesKey, err := base64.StdEncoding.DecodeString(base64AESKey + "=")
+	if err != nil {
+		return nil, fmt.Errorf("Decode base64AESKey:%s failed", base64AESKey)
+	}
+	return aesKey, nil
+}
+
+// SetAESKey 设置新的AESKey, 原来的AESKey作为旧值继续用于解密, 直到调用 RemovePrevAESKey
+func (srv *Server) SetAESKey(base64AESKey string) error {
+	aesKey, err := decodeAESKey(base64AESKey)
+	if err != nil {
+		return err
+	}
+	srv.keyMu.Lock()
+	defer srv.keyMu.Unlock()
+	srv.keys.prevAESKey = srv.keys.aesKey
+	srv.keys.aesKey = aesKey
+	return nil
+}
+
+// RemovePrevAESKey 微信后台修改生效后删除旧的AESKey
+func (srv *Server) RemovePrevAESKey() {
+	srv.keyMu.Lock()
+	defer srv.keyMu.Unlock()
+	srv.keys.prevAESKey = nil
+}
+
+// SetToken 设置新的Token, 原来的Token作为旧值继续用于验证签名, 直到调用 RemovePrevToken
+func (srv *Server) SetToken(token string) {
+	srv.keyMu.Lock()
+	defer srv.keyMu.Unlock()
+	srv.keys.prevToken = srv.keys.token
+	srv.keys.token = token
+}
+
+// RemovePrevToken 微信后台修改生效后删除旧的Token
+func (srv *Server) RemovePrevToken() {
+	srv.keyMu.Lock()
+	defer srv.keyMu.Unlock()
+	srv.keys.prevToken = ""
+}
+
+// 当前和旧的AESKey, 当前的在前
+func (srv *Server) getAESKeys() [][]byte {
+	srv.keyMu.RLock()
+	defer srv.keyMu.RUnlock()
+	keys := make([][]byte, 0, 2)
+	if srv.keys.aesKey != nil {
+		keys = append(keys, srv.keys.aesKey)
+	}
+	if srv.keys.prevAESKey != nil {
+		keys = append(keys, srv.keys.prevAESKey)
+	}
+	return keys
+}
+
+// 当前和旧的Token, 当前的在前
+func (srv *Server) getTokens() []string {
+	srv.keyMu.RLock()
+	defer srv.keyMu.RUnlock()
+	tokens := make([]string, 0, 2)
+	if srv.keys.token != "" {
+		tokens = append(tokens, srv.keys.token)
+	}
+	if srv.keys.prevToken != "" {
+		tokens = append(tokens, srv.keys.prevToken)
+	}
+	return tokens
+}
+
+// 返回与签名匹配的Token
+func (srv *Server) matchToken(signature, timestamp, nonce string) (string, bool) {
+	for _, token := range srv.getTokens() {
+		if util.Sign(token, timestamp, nonce) == signature {
+			return token, true
+		}
+	}
+	return "", false
+}
+
+// 依次尝试当前和旧的AESKey解密消息
+func (srv *Server) decryptMsg(encryptedMsg []byte) (msgPlaintext, appId []byte, err error) {
+	aesKeys := srv.getAESKeys()
+	if len(aesKeys) == 0 {
+		return nil, nil, errors.New("aes key was not set for Server, see NewServer function or Server.SetAESKey method")
+	}
+	wantAppId := srv.cfg.AppID
+	for _, aesKey := range aesKeys {
+		_, msgPlaintext, appId, err = util.AESDecryptMsg(encryptedMsg, aesKey)
+		if err != nil {
+			continue
+		}
+		if wantAppId != "" && string(appId) != wantAppId {
+			err = fmt.Errorf("the message AppId mismatch, have: %s, want: %s", appId, wantAppId)
+			continue
+		}
+		return msgPlaintext, appId, nil
+	}
+	return nil, nil, err
+}
@@ -76,4 +76,29 @@ type MixedMsg struct {
 	AuthorizationCode            string `json:"AuthorizationCode" xml:"AuthorizationCode"`
 	AuthorizationCodeExpiredTime string `json:"AuthorizationCodeExpiredTime" xml:"AuthorizationCodeExpiredTime"`
 	PreAuthCode                  string `json:"PreAuthCode" xml:"PreAuthCode"`
+
+	// 小程序审核结果推送
+	SuccTime   int64  `xml:"SuccTime"   json:"SuccTime"`
+	FailTime   int64  `xml:"FailTime"   json:"FailTime"`
+	DelayTime  int64  `xml:"DelayTime"  json:"DelayTime"`
+	Reason     string `xml:"Reason"     json:"Reason"`
+	ScreenShot string `xml:"ScreenShot" json:"ScreenShot"`
+
+	// 快速创建小程序推送
+	RegisterAppid  string            `json:"appid" xml:"appid"`
+	RegisterStatus int               `json:"status" xml:"status"`
+	AuthCode       string            `json:"auth_code" xml:"auth_code"`
+	RegisterMsg    string            `json:"msg" xml:"msg"`
+	RegisterInfo   *FastRegisterInfo `json:"info,omitempty" xml:"info,omitempty"`
+}
+
+// 处理方法的类型, 第三方平台推送为InfoType, 授权方的事件推送为Event, 其他消息为MsgType
+func (msg *MixedMsg) handlerType() string {
+	if msg.InfoType != "" {
+		return msg.InfoType
+	}
+	if msg.MsgType == "event" {
+		return msg.EventType
+	}
+	return msg.MsgType
 }
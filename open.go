@@ -19,18 +19,32 @@ type Config struct {
 	AppSecret string
 	AESKey    string
 	Token     string
+	// 修改AESKey或Token期间, 旧的值仍可用于解密和验证签名
+	PrevAESKey string
+	PrevToken  string
 	//RedirectUrl    string
 }
 type HandlerChain func(c Context)
 
+// 事件观察者, 在处理方法之前调用, 不需要写返回
+type Observer func(msg *MixedMsg)
+
 type Server struct {
 	sync.Mutex
 	cfg          Config
 	handlerMap   map[string]HandlerChain //方法处理
+	observers    map[string][]Observer
+	// Deprecated: 只读, 为创建时的AESKey, 不会随 SetAESKey 更新, 修改也不会生效, 解密使用 SetAESKey 设置的值
 	DecodeAesKey []byte
+	keyMu        sync.RWMutex
+	keys         callbackKeys
 	*Client
 	errorHandler WechatErrorer           // 错误处理
 	ticketServer TicketServer // ticket存储
+	authorizerTokenServer AuthorizerTokenServer // 授权方token存储
+	authorizerTokenMu     sync.Mutex            // 刷新授权方token
+	authorizerInfoCache   *AuthorizerInfoCache  // 授权方详情缓存
+	permissionGuard       bool                  // 调用前检查权限集
 	// 获取token
 	AccessTokenServer
 
@@ -49,13 +63,6 @@ const (
 	wechatApiUrl = "https://api.weixin.qq.com"
 )
 
-func (srv *Server) getAESKey() []byte {
-	return srv.DecodeAesKey
-}
-func (srv *Server) getToken() string {
-	return srv.cfg.Token
-}
-
 type cipherRequestHttpBody struct {
 	XMLName            struct{} `xml:"xml"`
 	ToUserName         string   `xml:"ToUserName"`
@@ -63,12 +70,30 @@ type cipherRequestHttpBody struct {
 	Base64EncryptedMsg []byte   `xml:"Encrypt"`
 }
 
+// NewService 创建Server, AESKey错误时panic, 需要返回错误和校验Config的请使用 NewServer
 func NewService(cfg Config, ticket TicketServer, cli *http.Client, tokenService AccessTokenServer, errHandler WechatErrorer) *Server {
+	srv, err := newServer(cfg, ticket, cli, tokenService, errHandler)
+	if err != nil {
+		panic(err.Error())
+	}
+	return srv
+}
+
+// NewServer 创建Server, 会先校验Config的每一项
+func NewServer(cfg Config, ticket TicketServer, cli *http.Client, tokenService AccessTokenServer, errHandler WechatErrorer) (*Server, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return newServer(cfg, ticket, cli, tokenService, errHandler)
+}
+
+func newServer(cfg Config, ticket TicketServer, cli *http.Client, tokenService AccessTokenServer, errHandler WechatErrorer) (*Server, error) {
 	if errHandler == nil {
 		errHandler = DefaultErrorHandler
 	}
 	if ticket == nil {
-		ticket = defaultTicketServerHander
+		// 每个Server单独保存ticket, 多个第三方平台时不会互相覆盖
+		ticket = &defaultTicketServer{}
 	}
 	client := NewClient(cli)
 	if tokenService == nil {
@@ -76,9 +101,12 @@ func NewService(cfg Config, ticket TicketServer, cli *http.Client, tokenService
 	}
 	srv := Server{
 		cfg:               cfg,
+		keys:              callbackKeys{token: cfg.Token, prevToken: cfg.PrevToken},
 		errorHandler:      errHandler,
 		handlerMap:        make(map[string]HandlerChain),
+		observers:         make(map[string][]Observer),
 		ticketServer:      ticket,
+		authorizerTokenServer: newDefaultAuthorizerTokenServer(),
 		Client:            client,
 		AccessTokenServer: tokenService,
 	}
@@ -93,22 +121,38 @@ func NewService(cfg Config, ticket TicketServer, cli *http.Client, tokenService
 	})
 	defer srv.Unlock()
 	if cfg.AESKey != "" {
-		if len(cfg.AESKey) != 43 {
-			panic("the length of base64AESKey must equal to 43")
+		aesKey, err := decodeAESKey(cfg.AESKey)
+		if err != nil {
+			return nil, err
 		}
-		var err error
-		srv.DecodeAesKey, err = base64.StdEncoding.DecodeString(cfg.AESKey + "=")
+		srv.DecodeAesKey = aesKey
+		srv.keys.aesKey = aesKey
+	}
+	if cfg.PrevAESKey != "" {
+		aesKey, err := decodeAESKey(cfg.PrevAESKey)
 		if err != nil {
-			panic(fmt.Sprintf("Decode base64AESKey:%s failed", cfg.AESKey))
+			return nil, err
 		}
+		srv.keys.prevAESKey = aesKey
 	}
-	return &srv
+	return &srv, nil
 }
 
+// 第三方平台appid
+func (srv *Server) AppID() string {
+	return srv.cfg.AppID
+}
+
+// 第三方平台推送按InfoType处理, 授权方的消息与事件推送按Event或MsgType处理
 func (srv *Server) AddHander(t string, hander HandlerChain) {
 	srv.handlerMap[t] = hander
 }
 
+// 添加事件观察者, 同一事件可以有多个, 不会覆盖AddHander添加的处理方法
+func (srv *Server) AddObserver(t string, observer Observer) {
+	srv.observers[t] = append(srv.observers[t], observer)
+}
+
 func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	query:=r.URL.Query()
 
@@ -143,16 +187,13 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 
-			var token string
-			currentToken := srv.getToken()
-			if currentToken == "" {
+			if len(srv.getTokens()) == 0 {
 				err = errors.New("token was not set for Server, see NewServer function or Server.SetToken method")
 				srv.errorHandler.ServeError(w, r, err)
 				return
 			}
-			token = currentToken
-			wantSignature := util.Sign(token, timestampString, nonce)
-			if haveSignature != wantSignature {
+			token, ok := srv.matchToken(haveSignature, timestampString, nonce)
+			if !ok {
 				return
 			}
 			requestHttpBody := cipherRequestHttpBody{}
@@ -182,22 +223,8 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			}
 			encryptedMsg = encryptedMsg[:encryptedMsgLen]
 
-			var aesKey []byte
-			aesKey = srv.getAESKey()
-			if aesKey == nil {
-				err = errors.New("aes key was not set for Server, see NewServer function or Server.SetAESKey method")
-				srv.errorHandler.ServeError(w, r, err)
-				return
-			}
-			_, msgPlaintext, haveAppIdBytes, err := util.AESDecryptMsg(encryptedMsg, aesKey)
+			msgPlaintext, _, err := srv.decryptMsg(encryptedMsg)
 			if err != nil {
-				return
-			}
-
-			haveAppId := string(haveAppIdBytes)
-			wantAppId := srv.cfg.AppID
-			if wantAppId != "" && haveAppId!=wantAppId {
-				err = fmt.Errorf("the message AppId mismatch, have: %s, want: %s", haveAppId, wantAppId)
 				srv.errorHandler.ServeError(w, r, err)
 				return
 			}
@@ -213,8 +240,17 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				MsgPlaintext:  msgPlaintext,
 				MixedMsg:      &mixedMsg,
 			}
-			hand, exit := srv.handlerMap[mixedMsg.InfoType]
+			msgType := mixedMsg.handlerType()
+			observers := srv.observers[msgType]
+			for _, observer := range observers {
+				observer(&mixedMsg)
+			}
+			hand, exit := srv.handlerMap[msgType]
 			if !exit {
+				if len(observers) > 0 {
+					w.Write(Success)
+					return
+				}
 				srv.errorHandler.ServeError(w, r, errors.New("no hander"))
 				return
 			}
@@ -244,10 +280,7 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		var token string
-		token = srv.getToken()
-		wantSignature := util.Sign(token, timestamp, nonce)
-		if haveSignature != wantSignature {
+		if _, ok := srv.matchToken(haveSignature, timestamp, nonce); !ok {
 			srv.errorHandler.ServeError(w, r, errors.New("sign error"))
 			return
 		}
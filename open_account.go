@@ -0,0 +1,59 @@
+package open_wechat
+
+import "github.com/owen-gxz/open-wechat/core"
+
+// 开放平台账号管理, 使用授权方token
+const (
+	OpenAccountCreateUrl = wechatApiUrl + "/cgi-bin/open/create?access_token=%s"
+	OpenAccountBindUrl   = wechatApiUrl + "/cgi-bin/open/bind?access_token=%s"
+	OpenAccountUnbindUrl = wechatApiUrl + "/cgi-bin/open/unbind?access_token=%s"
+	OpenAccountGetUrl    = wechatApiUrl + "/cgi-bin/open/get?access_token=%s"
+)
+
+type OpenAccountRequest struct {
+	Appid     string `json:"appid"`
+	OpenAppid string `json:"open_appid,omitempty"`
+}
+
+type OpenAccountResponse struct {
+	core.Error
+	// 开放平台帐号appid
+	OpenAppid string `json:"open_appid"`
+}
+
+// 创建开放平台帐号并绑定授权方
+func (srv *Server) CreateOpenAccount(authorizerAppid string) (*OpenAccountResponse, error) {
+	return srv.openAccount(OpenAccountCreateUrl, authorizerAppid, "")
+}
+
+// 将授权方绑定到开放平台帐号下
+func (srv *Server) BindOpenAccount(authorizerAppid, openAppid string) (*OpenAccountResponse, error) {
+	return srv.openAccount(OpenAccountBindUrl, authorizerAppid, openAppid)
+}
+
+// 将授权方从开放平台帐号下解绑
+func (srv *Server) UnbindOpenAccount(authorizerAppid, openAppid string) (*OpenAccountResponse, error) {
+	return srv.openAccount(OpenAccountUnbindUrl, authorizerAppid, openAppid)
+}
+
+// 获取授权方绑定的开放平台帐号
+func (srv *Server) GetOpenAccount(authorizerAppid string) (*OpenAccountResponse, error) {
+	return srv.openAccount(OpenAccountGetUrl, authorizerAppid, "")
+}
+
+func (srv *Server) openAccount(uri, authorizerAppid, openAppid string) (*OpenAccountResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeOpenAccount, FuncScopeWxaOpenAccount)
+	if err != nil {
+		return nil, err
+	}
+	req := OpenAccountRequest{
+		Appid:     authorizerAppid,
+		OpenAppid: openAppid,
+	}
+	resp := &OpenAccountResponse{}
+	err = srv.PostJson(getCompleteUrl(uri, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
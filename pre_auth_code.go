@@ -3,15 +3,21 @@ package open_wechat
 import (
 	"fmt"
 	"github.com/owen-gxz/open-wechat/core"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type AuthType string
 
 const (
-	PreAuthCodeUrl  = wechatApiUrl + "/cgi-bin/component/api_create_preauthcode?component_access_token=%s"
-	AuthPageUrl     = wechatApiUrl + "/cgi-bin/componentloginpage?component_appid=%s&pre_auth_code=%s&redirect_uri=%s&auth_type=%s"
-	QueryAuthUrl    = wechatApiUrl + "/cgi-bin/component/api_query_auth?component_access_token=%s"
-	RefreshTokenUrl = wechatApiUrl + "/cgi-bin/component/api_authorizer_token?component_access_token=%s"
+	PreAuthCodeUrl = wechatApiUrl + "/cgi-bin/component/api_create_preauthcode?component_access_token=%s"
+	AuthPageUrl    = "https://mp.weixin.qq.com/cgi-bin/componentloginpage?component_appid=%s&pre_auth_code=%s&redirect_uri=%s&auth_type=%s"
+	// 移动端授权链接, 需要在微信客户端中打开
+	MobileAuthPageUrl = "https://open.weixin.qq.com/wxaopen/safe/bindcomponent?action=bindcomponent&no_scan=1&component_appid=%s&pre_auth_code=%s&redirect_uri=%s&auth_type=%s"
+	QueryAuthUrl      = wechatApiUrl + "/cgi-bin/component/api_query_auth?component_access_token=%s"
+	RefreshTokenUrl   = wechatApiUrl + "/cgi-bin/component/api_authorizer_token?component_access_token=%s"
 
 	PreAuthAuthTypeAll     AuthType = "3" // 全部
 	PreAuthAuthTypeMinapp  AuthType = "2" // 小程序
@@ -44,12 +50,58 @@ func (srv *Server) PreAuthCode() (*PreAuthCodeResponse, error) {
 	return resp, nil
 }
 
+// 获取授权链接, 出错时返回空字符串, 需要错误信息的请使用 AuthLink
 func (srv *Server) AuthUrl(redirectUri string, authType AuthType) string {
-	pcode, err := srv.PreAuthCode()
+	link, err := srv.AuthLink(AuthLinkRequest{
+		RedirectUri: redirectUri,
+		AuthType:    authType,
+	})
 	if err != nil {
 		return ""
 	}
-	return fmt.Sprintf(AuthPageUrl, srv.cfg.AppID, pcode.PreAuthCode, redirectUri, authType)
+	return link
+}
+
+type AuthLinkRequest struct {
+	RedirectUri string
+	AuthType    AuthType
+	// 指定授权唯一的公众号或小程序appid, 为空时由用户选择
+	BizAppid string
+	// 指定的权限集id列表, 为空时使用已全网发布的权限集
+	CategoryList []int
+	// 生成移动端链接
+	Mobile bool
+}
+
+// 获取授权链接, 每次都会生成新的预授权码
+func (srv *Server) AuthLink(req AuthLinkRequest) (string, error) {
+	pcode, err := srv.PreAuthCode()
+	if err != nil {
+		return "", err
+	}
+	if err = pcode.Err(); err != nil {
+		return "", err
+	}
+	pageUrl := AuthPageUrl
+	if req.Mobile {
+		pageUrl = MobileAuthPageUrl
+	}
+	link := fmt.Sprintf(pageUrl, url.QueryEscape(srv.cfg.AppID), url.QueryEscape(pcode.PreAuthCode),
+		url.QueryEscape(req.RedirectUri), url.QueryEscape(string(req.AuthType)))
+	if req.BizAppid != "" {
+		link += "&biz_appid=" + url.QueryEscape(req.BizAppid)
+	}
+	if len(req.CategoryList) > 0 {
+		categories := make([]string, len(req.CategoryList))
+		for i, id := range req.CategoryList {
+			categories[i] = strconv.Itoa(id)
+		}
+		link += "&category_list=" + url.QueryEscape(strings.Join(categories, "|"))
+	}
+	if req.Mobile {
+		link += "#wechat_redirect"
+	}
+	return link, nil
 }
 
 type QueryAuthRequest struct {
@@ -59,19 +111,15 @@ type QueryAuthRequest struct {
 type QueryAuthResponse struct {
 	core.Error
 	AuthorizationInfo struct {
-		AuthorizerAppid        string `json:"authorizer_appid"`
-		AuthorizerAccessToken  string `json:"authorizer_access_token"`
-		ExpiresIn              int    `json:"expires_in"`
-		AuthorizerRefreshToken string `json:"authorizer_refresh_token"`
-		FuncInfo               []struct {
-			FuncscopeCategory struct {
-				ID int `json:"id"`
-			} `json:"funcscope_category"`
-		} `json:"func_info"`
+		AuthorizerAppid        string       `json:"authorizer_appid"`
+		AuthorizerAccessToken  string       `json:"authorizer_access_token"`
+		ExpiresIn              int          `json:"expires_in"`
+		AuthorizerRefreshToken string       `json:"authorizer_refresh_token"`
+		FuncInfo               FuncInfoList `json:"func_info"`
 	} `json:"authorization_info"`
 }
 
-// 返回授权数据
+// 返回授权数据, 成功时token会保存到授权方token存储中
 func (srv *Server) QueryAuth(code string) (*QueryAuthResponse, error) {
 	accessToken, err := srv.Token()
 	if err != nil {
@@ -86,6 +134,18 @@ func (srv *Server) QueryAuth(code string) (*QueryAuthResponse, error) {
 	if err != nil {
 		return nil, err
 	}
+	if resp.ErrCode == 0 {
+		info := resp.AuthorizationInfo
+		err = srv.authorizerTokenServer.SetAuthorizerToken(&AuthorizerToken{
+			AuthorizerAppid:        info.AuthorizerAppid,
+			AuthorizerAccessToken:  info.AuthorizerAccessToken,
+			AuthorizerRefreshToken: info.AuthorizerRefreshToken,
+			ExpiresIn:              time.Now().Unix() + int64(info.ExpiresIn),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 	return resp, nil
 }
 
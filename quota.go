@@ -0,0 +1,155 @@
+package open_wechat
+
+import "github.com/owen-gxz/open-wechat/core"
+
+// api调用次数管理
+const (
+	ComponentClearQuotaUrl  = wechatApiUrl + "/cgi-bin/component/clear_quota?component_access_token=%s"
+	AuthorizerClearQuotaUrl = wechatApiUrl + "/cgi-bin/clear_quota?access_token=%s"
+	QuotaUrl                = wechatApiUrl + "/cgi-bin/openapi/quota/get?access_token=%s"
+	RidUrl                  = wechatApiUrl + "/cgi-bin/openapi/rid/get?access_token=%s"
+)
+
+type ComponentClearQuotaRequest struct {
+	ComponentAppid string `json:"component_appid"`
+}
+
+type ClearQuotaResponse struct {
+	core.Error
+}
+
+// 第三方平台对其所有api调用次数清零
+func (srv *Server) ComponentClearQuota() (*ClearQuotaResponse, error) {
+	accessToken, err := srv.Token()
+	if err != nil {
+		return nil, err
+	}
+	req := ComponentClearQuotaRequest{
+		ComponentAppid: srv.cfg.AppID,
+	}
+	resp := &ClearQuotaResponse{}
+	err = srv.PostJson(getCompleteUrl(ComponentClearQuotaUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type AuthorizerClearQuotaRequest struct {
+	Appid string `json:"appid"`
+}
+
+// 授权方对其所有api调用次数清零
+func (srv *Server) AuthorizerClearQuota(authorizerAppid string) (*ClearQuotaResponse, error) {
+	accessToken, err := srv.AuthorizerToken(authorizerAppid)
+	if err != nil {
+		return nil, err
+	}
+	req := AuthorizerClearQuotaRequest{
+		Appid: authorizerAppid,
+	}
+	resp := &ClearQuotaResponse{}
+	err = srv.PostJson(getCompleteUrl(AuthorizerClearQuotaUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type QuotaRequest struct {
+	CgiPath string `json:"cgi_path"`
+}
+
+type QuotaResponse struct {
+	core.Error
+	Quota struct {
+		//当天该账号可调用该接口的次数
+		DailyLimit int64 `json:"daily_limit"`
+		//当天已经调用的次数
+		Used int64 `json:"used"`
+		//当天剩余调用次数
+		Remain int64 `json:"remain"`
+	} `json:"quota"`
+}
+
+// 查询第三方平台某个api的调用额度, cgiPath如: /cgi-bin/component/api_query_auth
+func (srv *Server) ComponentQuota(cgiPath string) (*QuotaResponse, error) {
+	accessToken, err := srv.Token()
+	if err != nil {
+		return nil, err
+	}
+	return srv.quota(accessToken, cgiPath)
+}
+
+// 查询授权方某个api的调用额度
+func (srv *Server) AuthorizerQuota(authorizerAppid, cgiPath string) (*QuotaResponse, error) {
+	accessToken, err := srv.AuthorizerToken(authorizerAppid)
+	if err != nil {
+		return nil, err
+	}
+	return srv.quota(accessToken, cgiPath)
+}
+
+func (srv *Server) quota(accessToken, cgiPath string) (*QuotaResponse, error) {
+	req := QuotaRequest{
+		CgiPath: cgiPath,
+	}
+	resp := &QuotaResponse{}
+	err := srv.PostJson(getCompleteUrl(QuotaUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type RidRequest struct {
+	Rid string `json:"rid"`
+}
+
+type RidResponse struct {
+	core.Error
+	Request struct {
+		//发起请求的时间戳
+		InvokeTime int64 `json:"invoke_time"`
+		//请求毫秒级耗时
+		CostInMs int64 `json:"cost_in_ms"`
+		//请求的URL参数
+		RequestUrl string `json:"request_url"`
+		//post请求的请求参数
+		RequestBody string `json:"request_body"`
+		//接口请求返回参数
+		ResponseBody string `json:"response_body"`
+		//接口请求的客户端ip
+		ClientIp string `json:"client_ip"`
+	} `json:"request"`
+}
+
+// 查询第三方平台接口报错的rid详情, rid可以通过 core.Error.Rid 获取
+func (srv *Server) ComponentRid(rid string) (*RidResponse, error) {
+	accessToken, err := srv.Token()
+	if err != nil {
+		return nil, err
+	}
+	return srv.rid(accessToken, rid)
+}
+
+// 查询授权方接口报错的rid详情
+func (srv *Server) AuthorizerRid(authorizerAppid, rid string) (*RidResponse, error) {
+	accessToken, err := srv.AuthorizerToken(authorizerAppid)
+	if err != nil {
+		return nil, err
+	}
+	return srv.rid(accessToken, rid)
+}
+
+func (srv *Server) rid(accessToken, rid string) (*RidResponse, error) {
+	req := RidRequest{
+		Rid: rid,
+	}
+	resp := &RidResponse{}
+	err := srv.PostJson(getCompleteUrl(RidUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
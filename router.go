@@ -0,0 +1,111 @@
+package open_wechat
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// Router 多个第三方平台共用一个推送地址时, 按推送中的AppId分发到对应的Server
+// 每个Server使用自己的Config, ticket存储, token获取和事件处理方法
+type Router struct {
+	sync.RWMutex
+	servers      map[string]*Server
+	errorHandler WechatErrorer
+}
+
+func NewRouter(errHandler WechatErrorer) *Router {
+	if errHandler == nil {
+		errHandler = DefaultErrorHandler
+	}
+	return &Router{
+		servers:      make(map[string]*Server),
+		errorHandler: errHandler,
+	}
+}
+
+// 注册第三方平台, 以Config.AppID区分
+func (rt *Router) Register(srv *Server) {
+	rt.Lock()
+	defer rt.Unlock()
+	rt.servers[srv.AppID()] = srv
+}
+
+// 获取第三方平台对应的Server
+func (rt *Router) Server(appid string) (*Server, bool) {
+	rt.RLock()
+	defer rt.RUnlock()
+	srv, ok := rt.servers[appid]
+	return srv, ok
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var appid string
+	var encryptedMsg []byte
+	if r.Method == "POST" {
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			rt.errorHandler.ServeError(w, r, err)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(data))
+		requestHttpBody := cipherRequestHttpBody{}
+		if err = xml.Unmarshal(data, &requestHttpBody); err != nil {
+			rt.errorHandler.ServeError(w, r, err)
+			return
+		}
+		appid = requestHttpBody.AppId
+		encryptedMsg = requestHttpBody.Base64EncryptedMsg
+	}
+	var srv *Server
+	if appid != "" {
+		var ok bool
+		if srv, ok = rt.Server(appid); !ok {
+			rt.errorHandler.ServeError(w, r, fmt.Errorf("no server for AppId: %s", appid))
+			return
+		}
+	} else {
+		// 验证回调地址和授权方消息推送不带AppId, 按Token签名查找
+		query := r.URL.Query()
+		srv = rt.matchServer(query.Get("signature"), query.Get("timestamp"), query.Get("nonce"), encryptedMsg)
+		if srv == nil {
+			rt.errorHandler.ServeError(w, r, errors.New("no server matches the signature"))
+			return
+		}
+	}
+	srv.ServeHTTP(w, r)
+}
+
+// 多个Server的Token相同时, 用各自的AESKey尝试解密, 解密出的AppId一致的为对应的Server
+func (rt *Router) matchServer(signature, timestamp, nonce string, base64EncryptedMsg []byte) *Server {
+	rt.RLock()
+	defer rt.RUnlock()
+	var candidates []*Server
+	for _, srv := range rt.servers {
+		if _, ok := srv.matchToken(signature, timestamp, nonce); ok {
+			candidates = append(candidates, srv)
+		}
+	}
+	if len(candidates) <= 1 || len(base64EncryptedMsg) == 0 {
+		if len(candidates) == 0 {
+			return nil
+		}
+		return candidates[0]
+	}
+	encryptedMsg := make([]byte, base64.StdEncoding.DecodedLen(len(base64EncryptedMsg)))
+	n, err := base64.StdEncoding.Decode(encryptedMsg, base64EncryptedMsg)
+	if err != nil {
+		return nil
+	}
+	for _, srv := range candidates {
+		if _, _, err = srv.decryptMsg(encryptedMsg[:n]); err == nil {
+			return srv
+		}
+	}
+	return nil
+}
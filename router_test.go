@@ -0,0 +1,70 @@
+package open_wechat
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/owen-gxz/open-wechat/util"
+)
+
+func newTestServer(t *testing.T, appid, token, aesKey string) *Server {
+	srv, err := NewServer(Config{AppID: appid, AppSecret: "secret", Token: token, AESKey: aesKey}, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv
+}
+
+// 授权方消息推送, 不带AppId
+func newTestPush(t *testing.T, srv *Server, msg string) *http.Request {
+	aesKey, err := decodeAESKey(srv.cfg.AESKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext := util.AESEncryptMsg([]byte("0123456789abcdef"), []byte(msg), srv.AppID(), aesKey)
+	encrypted := base64.StdEncoding.EncodeToString(ciphertext)
+	timestamp, nonce := "1600000000", "nonce"
+	query := url.Values{
+		"encrypt_type":  {"aes"},
+		"timestamp":     {timestamp},
+		"nonce":         {nonce},
+		"signature":     {util.Sign(srv.cfg.Token, timestamp, nonce)},
+		"msg_signature": {util.MsgSign(srv.cfg.Token, timestamp, nonce, encrypted)},
+	}
+	body := "<xml><ToUserName>gh_test</ToUserName><Encrypt>" + encrypted + "</Encrypt></xml>"
+	return httptest.NewRequest("POST", "/?"+query.Encode(), strings.NewReader(body))
+}
+
+func TestRouterSharedToken(t *testing.T) {
+	srvA := newTestServer(t, "wxcomponenta", "token", strings.Repeat("a", 43))
+	srvB := newTestServer(t, "wxcomponentb", "token", strings.Repeat("b", 43))
+	received := make(map[string]int)
+	for _, srv := range []*Server{srvA, srvB} {
+		appid := srv.AppID()
+		srv.AddHander("text", func(c Context) {
+			received[appid]++
+			c.w.Write(Success)
+		})
+	}
+	rt := NewRouter(nil)
+	rt.Register(srvA)
+	rt.Register(srvB)
+
+	const n = 20
+	for i := 0; i < n; i++ {
+		for _, srv := range []*Server{srvA, srvB} {
+			w := httptest.NewRecorder()
+			rt.ServeHTTP(w, newTestPush(t, srv, "<xml><MsgType>text</MsgType></xml>"))
+			if w.Body.String() != string(Success) {
+				t.Fatalf("push to %s: response %q", srv.AppID(), w.Body.String())
+			}
+		}
+	}
+	if received[srvA.AppID()] != n || received[srvB.AppID()] != n {
+		t.Fatalf("received %v, want %d each", received, n)
+	}
+}
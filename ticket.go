@@ -11,9 +11,6 @@ type defaultTicketServer struct {
 	ComponentTicketCache string // *accessToken
 }
 
-var defaultTicketServerHander TicketServer = &defaultTicketServer{}
-
-
 var _ TicketServer = (*defaultTicketServer)(nil)
 
 func (cts *defaultTicketServer) GetTicket() (string, error) {
@@ -0,0 +1,254 @@
+package open_wechat
+
+import "github.com/owen-gxz/open-wechat/core"
+
+// 代小程序审核管理, 使用授权方token
+const (
+	SubmitAuditUrl       = wechatApiUrl + "/wxa/submit_audit?access_token=%s"
+	AuditStatusUrl       = wechatApiUrl + "/wxa/get_auditstatus?access_token=%s"
+	LatestAuditStatusUrl = wechatApiUrl + "/wxa/get_latest_auditstatus?access_token=%s"
+	UndoCodeAuditUrl     = wechatApiUrl + "/wxa/undocodeaudit?access_token=%s"
+	SpeedupAuditUrl      = wechatApiUrl + "/wxa/speedupaudit?access_token=%s"
+	AuditQuotaUrl        = wechatApiUrl + "/wxa/queryquota?access_token=%s"
+
+	// EventWeappAuditSuccess 审核通过
+	EventWeappAuditSuccess = "weapp_audit_success"
+	// EventWeappAuditFail 审核不通过
+	EventWeappAuditFail = "weapp_audit_fail"
+	// EventWeappAuditDelay 审核延后
+	EventWeappAuditDelay = "weapp_audit_delay"
+)
+
+type AuditStatus int
+
+// 审核状态
+const (
+	AuditStatusSuccess  AuditStatus = 0 // 审核成功
+	AuditStatusRejected AuditStatus = 1 // 审核被拒绝
+	AuditStatusAuditing AuditStatus = 2 // 审核中
+	AuditStatusUndone   AuditStatus = 3 // 已撤回
+	AuditStatusDelayed  AuditStatus = 4 // 审核延后
+)
+
+// 审核项, 为空时使用小程序的默认类目
+type AuditItem struct {
+	// 小程序的页面
+	Address string `json:"address,omitempty"`
+	// 标签, 多个用空格分隔
+	Tag         string `json:"tag,omitempty"`
+	FirstClass  string `json:"first_class,omitempty"`
+	SecondClass string `json:"second_class,omitempty"`
+	ThirdClass  string `json:"third_class,omitempty"`
+	FirstId     int    `json:"first_id,omitempty"`
+	SecondId    int    `json:"second_id,omitempty"`
+	ThirdId     int    `json:"third_id,omitempty"`
+	// 页面标题
+	Title string `json:"title,omitempty"`
+}
+
+// 预览信息, 通过上传临时素材获取的media_id
+type AuditPreviewInfo struct {
+	VideoIdList []string `json:"video_id_list,omitempty"`
+	PicIdList   []string `json:"pic_id_list,omitempty"`
+}
+
+// 用户生成内容场景(UGC)信息安全声明
+type UgcDeclare struct {
+	// 0 不涉及用户生成内容, 1 用户资料, 2 图片, 3 视频, 4 文本, 5 其他
+	Scene          []int  `json:"scene,omitempty"`
+	OtherSceneDesc string `json:"other_scene_desc,omitempty"`
+	// 1 使用平台建议的内容安全API, 2 使用其他的内容审核产品, 3 通过人工审核把关, 4 未做内容审核把关
+	Method []int `json:"method,omitempty"`
+	// 0 无审核团队, 1 有审核团队
+	HasAuditTeam int    `json:"has_audit_team,omitempty"`
+	AuditDesc    string `json:"audit_desc,omitempty"`
+}
+
+type SubmitAuditRequest struct {
+	ItemList    []AuditItem       `json:"item_list,omitempty"`
+	PreviewInfo *AuditPreviewInfo `json:"preview_info,omitempty"`
+	// 小程序版本说明和功能解释
+	VersionDesc string `json:"version_desc,omitempty"`
+	// 反馈内容
+	FeedbackInfo string `json:"feedback_info,omitempty"`
+	// 反馈的图片media_id, 多个用|分隔
+	FeedbackStuff string      `json:"feedback_stuff,omitempty"`
+	UgcDeclare    *UgcDeclare `json:"ugc_declare,omitempty"`
+}
+
+type SubmitAuditResponse struct {
+	core.Error
+	// 审核编号
+	Auditid int64 `json:"auditid"`
+}
+
+// 提交审核, 结果通过审核事件推送, 见 OnAuditResult
+func (srv *Server) SubmitAudit(authorizerAppid string, req *SubmitAuditRequest) (*SubmitAuditResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	resp := &SubmitAuditResponse{}
+	err = srv.PostJson(getCompleteUrl(SubmitAuditUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type AuditidRequest struct {
+	Auditid int64 `json:"auditid"`
+}
+
+type AuditStatusResponse struct {
+	core.Error
+	Status AuditStatus `json:"status"`
+	// 审核被拒绝的原因
+	Reason string `json:"reason"`
+	// 审核不通过的截图media_id, 多个用|分隔
+	ScreenShot string `json:"screenshot"`
+}
+
+// 查询指定版本的审核状态
+func (srv *Server) AuditStatus(authorizerAppid string, auditid int64) (*AuditStatusResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	req := AuditidRequest{
+		Auditid: auditid,
+	}
+	resp := &AuditStatusResponse{}
+	err = srv.PostJson(getCompleteUrl(AuditStatusUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type LatestAuditStatusResponse struct {
+	core.Error
+	Auditid         int64       `json:"auditid"`
+	Status          AuditStatus `json:"status"`
+	Reason          string      `json:"reason"`
+	ScreenShot      string      `json:"ScreenShot"`
+	UserVersion     string      `json:"user_version"`
+	UserDesc        string      `json:"user_desc"`
+	SubmitAuditTime int64       `json:"submit_audit_time"`
+}
+
+// 查询最新一次提交的审核状态
+func (srv *Server) LatestAuditStatus(authorizerAppid string) (*LatestAuditStatusResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	resp := &LatestAuditStatusResponse{}
+	err = srv.GetJson(getCompleteUrl(LatestAuditStatusUrl, accessToken), resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type AuditResponse struct {
+	core.Error
+}
+
+// 撤回审核, 单个小程序每天只能撤回1次
+func (srv *Server) UndoCodeAudit(authorizerAppid string) (*AuditResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	resp := &AuditResponse{}
+	err = srv.GetJson(getCompleteUrl(UndoCodeAuditUrl, accessToken), resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// 加急审核
+func (srv *Server) SpeedupAudit(authorizerAppid string, auditid int64) (*AuditResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	req := AuditidRequest{
+		Auditid: auditid,
+	}
+	resp := &AuditResponse{}
+	err = srv.PostJson(getCompleteUrl(SpeedupAuditUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type AuditQuotaResponse struct {
+	core.Error
+	// 当月剩余提交审核次数
+	Rest  int `json:"rest"`
+	Limit int `json:"limit"`
+	// 剩余加急次数
+	SpeedupRest  int `json:"speedup_rest"`
+	SpeedupLimit int `json:"speedup_limit"`
+}
+
+// 查询服务商的审核额度
+func (srv *Server) AuditQuota(authorizerAppid string) (*AuditQuotaResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	resp := &AuditQuotaResponse{}
+	err = srv.GetJson(getCompleteUrl(AuditQuotaUrl, accessToken), resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// 审核结果推送
+type AuditResult struct {
+	// 小程序原始ID
+	UserName string
+	// EventWeappAuditSuccess, EventWeappAuditFail 或 EventWeappAuditDelay
+	Event  string
+	Status AuditStatus
+	// 审核不通过或延后的原因
+	Reason     string
+	ScreenShot string
+	// 审核通过, 不通过或延后的时间
+	Time int64
+}
+
+// 处理审核结果推送, 推送到授权方的消息与事件接收URL, fn不需要写返回
+func (srv *Server) OnAuditResult(fn func(c Context, result *AuditResult)) {
+	handler := func(c Context) {
+		msg := c.MixedMsg
+		result := &AuditResult{
+			UserName:   msg.ToUserName,
+			Event:      msg.EventType,
+			Reason:     msg.Reason,
+			ScreenShot: msg.ScreenShot,
+		}
+		switch msg.EventType {
+		case EventWeappAuditSuccess:
+			result.Status = AuditStatusSuccess
+			result.Time = msg.SuccTime
+		case EventWeappAuditFail:
+			result.Status = AuditStatusRejected
+			result.Time = msg.FailTime
+		case EventWeappAuditDelay:
+			result.Status = AuditStatusDelayed
+			result.Time = msg.DelayTime
+		}
+		fn(c, result)
+		c.w.Write(Success)
+	}
+	srv.AddHander(EventWeappAuditSuccess, handler)
+	srv.AddHander(EventWeappAuditFail, handler)
+	srv.AddHander(EventWeappAuditDelay, handler)
+}
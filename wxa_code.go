@@ -0,0 +1,74 @@
+package open_wechat
+
+import (
+	"github.com/owen-gxz/open-wechat/core"
+	"net/url"
+)
+
+// 代小程序代码管理, 使用授权方token
+const (
+	CommitCodeUrl       = wechatApiUrl + "/wxa/commit?access_token=%s"
+	CodePageUrl         = wechatApiUrl + "/wxa/get_page?access_token=%s"
+	ExperienceQrcodeUrl = wechatApiUrl + "/wxa/get_qrcode?access_token=%s"
+)
+
+type CommitCodeRequest struct {
+	// 代码库中的代码模板id
+	TemplateId int64 `json:"template_id"`
+	// 第三方自定义的配置, json字符串
+	ExtJson string `json:"ext_json"`
+	// 代码版本号
+	UserVersion string `json:"user_version"`
+	// 代码描述
+	UserDesc string `json:"user_desc"`
+}
+
+type CommitCodeResponse struct {
+	core.Error
+}
+
+// 上传小程序代码
+func (srv *Server) CommitCode(authorizerAppid string, req *CommitCodeRequest) (*CommitCodeResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	resp := &CommitCodeResponse{}
+	err = srv.PostJson(getCompleteUrl(CommitCodeUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type CodePageResponse struct {
+	core.Error
+	PageList []string `json:"page_list"`
+}
+
+// 获取已上传代码的页面列表
+func (srv *Server) CodePage(authorizerAppid string) (*CodePageResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	resp := &CodePageResponse{}
+	err = srv.GetJson(getCompleteUrl(CodePageUrl, accessToken), resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// 获取体验版二维码图片, path为空时使用默认页面
+func (srv *Server) ExperienceQrcode(authorizerAppid, path string) ([]byte, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	uri := getCompleteUrl(ExperienceQrcodeUrl, accessToken)
+	if path != "" {
+		uri += "&path=" + url.QueryEscape(path)
+	}
+	return srv.GetBytes(uri)
+}
@@ -0,0 +1,369 @@
+package open_wechat
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type DeployStage string
+
+// 小程序发布进度: pending -> committed -> auditing -> approved -> released, 出错时为failed
+// 发布失败时停留在approved, 下次只重试发布
+const (
+	DeployStagePending   DeployStage = "pending"
+	DeployStageCommitted DeployStage = "committed"
+	DeployStageAuditing  DeployStage = "auditing"
+	DeployStageApproved  DeployStage = "approved"
+	DeployStageReleased  DeployStage = "released"
+	DeployStageFailed    DeployStage = "failed"
+)
+
+const (
+	// 已经有正在审核的版本
+	errCodeAuditExists = 85009
+	// 小程序已发布过该版本
+	errCodeAlreadyReleased = 85052
+)
+
+const (
+	defaultDeployConcurrency       = 10
+	defaultDeployAuditPollInterval = 10 * time.Minute
+)
+
+// 单个小程序的发布状态
+type DeployState struct {
+	AuthorizerAppid string      `json:"authorizer_appid"`
+	TemplateId      int64       `json:"template_id"`
+	UserVersion     string      `json:"user_version"`
+	Stage           DeployStage `json:"stage"`
+	Auditid         int64       `json:"auditid"`
+	// 失败原因
+	Error     string `json:"error"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// 发布状态存储, 进程重启后从保存的状态继续, 未找到时返回nil, nil
+type DeployStore interface {
+	SaveDeployState(state *DeployState) error
+	GetDeployState(templateId int64, authorizerAppid string) (*DeployState, error)
+}
+
+type defaultDeployStore struct {
+	sync.RWMutex
+	states map[string]DeployState
+}
+
+var _ DeployStore = (*defaultDeployStore)(nil)
+
+func deployStateKey(templateId int64, authorizerAppid string) string {
+	return fmt.Sprintf("%d:%s", templateId, authorizerAppid)
+}
+
+func (d *defaultDeployStore) SaveDeployState(state *DeployState) error {
+	d.Lock()
+	defer d.Unlock()
+	d.states[deployStateKey(state.TemplateId, state.AuthorizerAppid)] = *state
+	return nil
+}
+
+func (d *defaultDeployStore) GetDeployState(templateId int64, authorizerAppid string) (*DeployState, error) {
+	d.RLock()
+	defer d.RUnlock()
+	state, ok := d.states[deployStateKey(templateId, authorizerAppid)]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+// Deployer 将代码模板发布到多个小程序: 上传代码 -> 提交审核 -> 等待审核 -> 发布
+type Deployer struct {
+	srv     *Server
+	store   DeployStore
+	extJson func(authorizerAppid string) (string, error)
+
+	// 同时发布的小程序数量, 默认10
+	Concurrency int
+	// 查询审核状态的间隔, 默认10分钟
+	AuditPollInterval time.Duration
+	// 每个小程序的提交审核参数, 默认使用小程序的默认类目
+	SubmitAuditRequest func(authorizerAppid string) *SubmitAuditRequest
+}
+
+// store为nil时保存在内存中, extJson返回每个小程序的ext_json, 可以为nil
+func NewDeployer(srv *Server, store DeployStore, extJson func(authorizerAppid string) (string, error)) *Deployer {
+	if store == nil {
+		store = &defaultDeployStore{states: make(map[string]DeployState)}
+	}
+	return &Deployer{
+		srv:               srv,
+		store:             store,
+		extJson:           extJson,
+		Concurrency:       defaultDeployConcurrency,
+		AuditPollInterval: defaultDeployAuditPollInterval,
+	}
+}
+
+// 发布模板到授权方小程序, 已保存的进度会继续, 失败的会重新开始
+// 关闭stop后不再开始新的小程序和新的阶段, 已开始的保留当前进度, 返回每个小程序最后的状态
+func (d *Deployer) Deploy(templateId int64, userVersion, userDesc string, authorizerAppids []string, stop <-chan struct{}) []DeployState {
+	concurrency := d.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultDeployConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	states := make([]DeployState, len(authorizerAppids))
+	var wg sync.WaitGroup
+	for i, appid := range authorizerAppids {
+		select {
+		case <-stop:
+			states[i] = d.savedState(templateId, userVersion, appid)
+			continue
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(i int, appid string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			states[i] = d.deploy(templateId, userVersion, userDesc, appid, stop)
+		}(i, appid)
+	}
+	wg.Wait()
+	return states
+}
+
+// 已保存的状态, 没有保存过时为pending, 读取失败时为failed
+func (d *Deployer) savedState(templateId int64, userVersion, authorizerAppid string) DeployState {
+	state, err := d.loadState(templateId, userVersion, authorizerAppid)
+	if err != nil {
+		return DeployState{AuthorizerAppid: authorizerAppid, TemplateId: templateId, UserVersion: userVersion, Stage: DeployStageFailed, Error: err.Error()}
+	}
+	return state
+}
+
+func (d *Deployer) loadState(templateId int64, userVersion, authorizerAppid string) (DeployState, error) {
+	state, err := d.store.GetDeployState(templateId, authorizerAppid)
+	if err != nil {
+		return DeployState{}, err
+	}
+	// 没有保存过或保存的是其它版本时从头开始
+	if state == nil || state.UserVersion != userVersion {
+		return DeployState{AuthorizerAppid: authorizerAppid, TemplateId: templateId, UserVersion: userVersion, Stage: DeployStagePending}, nil
+	}
+	return *state, nil
+}
+
+func (d *Deployer) deploy(templateId int64, userVersion, userDesc, authorizerAppid string, stop <-chan struct{}) DeployState {
+	saved, err := d.loadState(templateId, userVersion, authorizerAppid)
+	if err != nil {
+		return DeployState{AuthorizerAppid: authorizerAppid, TemplateId: templateId, UserVersion: userVersion, Stage: DeployStageFailed, Error: err.Error()}
+	}
+	// 上次在提交审核后退出时可能已经提交成功但没有保存
+	resumed := saved.Stage == DeployStageCommitted
+	state := saved
+	if state.Stage == DeployStageFailed {
+		state = DeployState{AuthorizerAppid: authorizerAppid, TemplateId: templateId, UserVersion: userVersion, Stage: DeployStagePending}
+	}
+	for {
+		// 每个阶段开始前检查stop, 停止时返回已保存的状态
+		select {
+		case <-stop:
+			return saved
+		default:
+		}
+		err = nil
+		switch state.Stage {
+		case DeployStagePending:
+			err = d.commit(&state, userDesc)
+		case DeployStageCommitted:
+			err = d.submitAudit(&state, resumed)
+		case DeployStageAuditing:
+			var waiting bool
+			waiting, err = d.checkAudit(&state)
+			if err == nil && waiting {
+				select {
+				case <-stop:
+					return saved
+				case <-time.After(d.AuditPollInterval):
+				}
+				continue
+			}
+		case DeployStageApproved:
+			if err = d.release(&state); err != nil {
+				// 保留approved, 下次只重试发布
+				state.Error = err.Error()
+				state.UpdatedAt = time.Now().Unix()
+				if err = d.store.SaveDeployState(&state); err != nil {
+					state.Stage = DeployStageFailed
+					state.Error = err.Error()
+				}
+				return state
+			}
+		default:
+			return state
+		}
+		if err != nil {
+			state.Stage = DeployStageFailed
+			state.Error = err.Error()
+		}
+		state.UpdatedAt = time.Now().Unix()
+		if err = d.store.SaveDeployState(&state); err != nil {
+			state.Stage = DeployStageFailed
+			state.Error = err.Error()
+			return state
+		}
+		saved = state
+	}
+}
+
+func (d *Deployer) commit(state *DeployState, userDesc string) error {
+	req := &CommitCodeRequest{
+		TemplateId:  state.TemplateId,
+		UserVersion: state.UserVersion,
+		UserDesc:    userDesc,
+	}
+	if d.extJson != nil {
+		extJson, err := d.extJson(state.AuthorizerAppid)
+		if err != nil {
+			return err
+		}
+		req.ExtJson = extJson
+	}
+	resp, err := d.srv.CommitCode(state.AuthorizerAppid, req)
+	if err != nil {
+		return err
+	}
+	if err = resp.Err(); err != nil {
+		return err
+	}
+	state.Stage = DeployStageCommitted
+	return nil
+}
+
+func (d *Deployer) submitAudit(state *DeployState, resumed bool) error {
+	if resumed {
+		if ok, err := d.adoptLatestAudit(state); err != nil || ok {
+			return err
+		}
+	}
+	req := &SubmitAuditRequest{}
+	if d.SubmitAuditRequest != nil {
+		req = d.SubmitAuditRequest(state.AuthorizerAppid)
+	}
+	resp, err := d.srv.SubmitAudit(state.AuthorizerAppid, req)
+	if err != nil {
+		return err
+	}
+	if resp.ErrCode == errCodeAuditExists {
+		if ok, err := d.adoptLatestAudit(state); err != nil || ok {
+			return err
+		}
+	}
+	if err = resp.Err(); err != nil {
+		return err
+	}
+	state.Auditid = resp.Auditid
+	state.Stage = DeployStageAuditing
+	return nil
+}
+
+// 最新一次提交的审核是当前版本且没有被拒绝时使用它的auditid, 返回是否使用
+func (d *Deployer) adoptLatestAudit(state *DeployState) (bool, error) {
+	latest, err := d.srv.LatestAuditStatus(state.AuthorizerAppid)
+	if err != nil {
+		return false, err
+	}
+	if err = latest.Err(); err != nil {
+		return false, err
+	}
+	if latest.UserVersion != state.UserVersion {
+		return false, nil
+	}
+	switch latest.Status {
+	case AuditStatusAuditing, AuditStatusDelayed, AuditStatusSuccess:
+		state.Auditid = latest.Auditid
+		state.Stage = DeployStageAuditing
+		return true, nil
+	}
+	return false, nil
+}
+
+// 审核中或延后时返回true, 审核通过后进入approved
+func (d *Deployer) checkAudit(state *DeployState) (bool, error) {
+	resp, err := d.srv.AuditStatus(state.AuthorizerAppid, state.Auditid)
+	if err != nil {
+		return false, err
+	}
+	if err = resp.Err(); err != nil {
+		return false, err
+	}
+	switch resp.Status {
+	case AuditStatusAuditing, AuditStatusDelayed:
+		return true, nil
+	case AuditStatusSuccess:
+		state.Stage = DeployStageApproved
+		return false, nil
+	case AuditStatusRejected:
+		return false, fmt.Errorf("audit rejected: %s", resp.Reason)
+	default:
+		return false, fmt.Errorf("audit status: %d", resp.Status)
+	}
+}
+
+func (d *Deployer) release(state *DeployState) error {
+	resp, err := d.srv.Release(state.AuthorizerAppid)
+	if err != nil {
+		return err
+	}
+	if resp.ErrCode != 0 && resp.ErrCode != errCodeAlreadyReleased {
+		return resp.Err()
+	}
+	state.Stage = DeployStageReleased
+	state.Error = ""
+	return nil
+}
+
+// 小程序当前的版本
+type VersionInventoryItem struct {
+	AuthorizerAppid string
+	ReleaseVersion  string
+	ReleaseTime     int64
+	ExpVersion      string
+	ExpTime         int64
+	Err             error
+}
+
+// 获取小程序线上版和体验版的版本
+func (d *Deployer) Inventory(authorizerAppids []string) []VersionInventoryItem {
+	concurrency := d.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultDeployConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	items := make([]VersionInventoryItem, len(authorizerAppids))
+	var wg sync.WaitGroup
+	for i, appid := range authorizerAppids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, appid string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			items[i].AuthorizerAppid = appid
+			resp, err := d.srv.VersionInfo(appid)
+			if err == nil {
+				err = resp.Err()
+			}
+			if err != nil {
+				items[i].Err = err
+				return
+			}
+			items[i].ReleaseVersion = resp.ReleaseInfo.ReleaseVersion
+			items[i].ReleaseTime = resp.ReleaseInfo.ReleaseTime
+			items[i].ExpVersion = resp.ExpInfo.ExpVersion
+			items[i].ExpTime = resp.ExpInfo.ExpTime
+		}(i, appid)
+	}
+	wg.Wait()
+	return items
+}
@@ -0,0 +1,145 @@
+package open_wechat
+
+import "github.com/owen-gxz/open-wechat/core"
+
+// 代小程序域名管理, 使用授权方token
+const (
+	ModifyDomainUrl             = wechatApiUrl + "/wxa/modify_domain?access_token=%s"
+	ModifyDomainDirectlyUrl     = wechatApiUrl + "/wxa/modify_domain_directly?access_token=%s"
+	EffectiveDomainUrl          = wechatApiUrl + "/wxa/get_effective_domain?access_token=%s"
+	SetWebviewDomainUrl         = wechatApiUrl + "/wxa/setwebviewdomain?access_token=%s"
+	SetWebviewDomainDirectlyUrl = wechatApiUrl + "/wxa/setwebviewdomain_directly?access_token=%s"
+	WebviewDomainConfirmFileUrl = wechatApiUrl + "/wxa/get_webviewdomain_confirmfile?access_token=%s"
+)
+
+type DomainAction string
+
+// 域名操作
+const (
+	DomainActionAdd    DomainAction = "add"
+	DomainActionDelete DomainAction = "delete"
+	DomainActionSet    DomainAction = "set" // 覆盖
+	DomainActionGet    DomainAction = "get"
+)
+
+type ModifyDomainRequest struct {
+	Action DomainAction `json:"action"`
+	MiniProgramNetwork
+}
+
+type ModifyDomainResponse struct {
+	core.Error
+	MiniProgramNetwork
+}
+
+// 设置服务器域名, 需要先在第三方平台配置小程序服务器域名, action为get时network可以为nil
+func (srv *Server) ModifyDomain(authorizerAppid string, action DomainAction, network *MiniProgramNetwork) (*ModifyDomainResponse, error) {
+	return srv.modifyDomain(ModifyDomainUrl, authorizerAppid, action, network)
+}
+
+// 直接设置服务器域名, 不需要在第三方平台配置
+func (srv *Server) ModifyDomainDirectly(authorizerAppid string, action DomainAction, network *MiniProgramNetwork) (*ModifyDomainResponse, error) {
+	return srv.modifyDomain(ModifyDomainDirectlyUrl, authorizerAppid, action, network)
+}
+
+func (srv *Server) modifyDomain(uri, authorizerAppid string, action DomainAction, network *MiniProgramNetwork) (*ModifyDomainResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	req := ModifyDomainRequest{
+		Action: action,
+	}
+	if network != nil {
+		req.MiniProgramNetwork = *network
+	}
+	resp := &ModifyDomainResponse{}
+	err = srv.PostJson(getCompleteUrl(uri, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type EffectiveDomainResponse struct {
+	core.Error
+	// 通过ModifyDomain设置的
+	MpDomain MiniProgramNetwork `json:"mp_domain"`
+	// 第三方平台配置的
+	ThirdDomain MiniProgramNetwork `json:"third_domain"`
+	// 通过ModifyDomainDirectly设置的
+	DirectDomain MiniProgramNetwork `json:"direct_domain"`
+	// 最终生效的
+	EffectiveDomain MiniProgramNetwork `json:"effective_domain"`
+}
+
+// 获取生效的服务器域名
+func (srv *Server) EffectiveDomain(authorizerAppid string) (*EffectiveDomainResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	resp := &EffectiveDomainResponse{}
+	err = srv.PostJson(getCompleteUrl(EffectiveDomainUrl, accessToken), core.H{}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type SetWebviewDomainRequest struct {
+	Action        DomainAction `json:"action"`
+	WebviewDomain []string     `json:"webviewdomain,omitempty"`
+}
+
+type SetWebviewDomainResponse struct {
+	core.Error
+	WebviewDomain []string `json:"webviewdomain"`
+}
+
+// 设置业务域名, 需要先在第三方平台配置业务域名
+func (srv *Server) SetWebviewDomain(authorizerAppid string, action DomainAction, webviewDomain []string) (*SetWebviewDomainResponse, error) {
+	return srv.setWebviewDomain(SetWebviewDomainUrl, authorizerAppid, action, webviewDomain)
+}
+
+// 直接设置业务域名, 需要先将 WebviewDomainConfirmFile 返回的校验文件放到域名根目录
+func (srv *Server) SetWebviewDomainDirectly(authorizerAppid string, action DomainAction, webviewDomain []string) (*SetWebviewDomainResponse, error) {
+	return srv.setWebviewDomain(SetWebviewDomainDirectlyUrl, authorizerAppid, action, webviewDomain)
+}
+
+func (srv *Server) setWebviewDomain(uri, authorizerAppid string, action DomainAction, webviewDomain []string) (*SetWebviewDomainResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	req := SetWebviewDomainRequest{
+		Action:        action,
+		WebviewDomain: webviewDomain,
+	}
+	resp := &SetWebviewDomainResponse{}
+	err = srv.PostJson(getCompleteUrl(uri, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type DomainConfirmFileResponse struct {
+	core.Error
+	FileName    string `json:"file_name"`
+	FileContent string `json:"file_content"`
+}
+
+// 获取业务域名校验文件
+func (srv *Server) WebviewDomainConfirmFile(authorizerAppid string) (*DomainConfirmFileResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	resp := &DomainConfirmFileResponse{}
+	err = srv.PostJson(getCompleteUrl(WebviewDomainConfirmFileUrl, accessToken), core.H{}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,253 @@
+package open_wechat
+
+import (
+	"github.com/owen-gxz/open-wechat/core"
+	"strconv"
+)
+
+// 代小程序发布管理, 使用授权方token
+const (
+	ReleaseUrl                = wechatApiUrl + "/wxa/release?access_token=%s"
+	RevertCodeReleaseUrl      = wechatApiUrl + "/wxa/revertcoderelease?access_token=%s"
+	GrayReleaseUrl            = wechatApiUrl + "/wxa/grayrelease?access_token=%s"
+	GrayReleasePlanUrl        = wechatApiUrl + "/wxa/getgrayreleaseplan?access_token=%s"
+	RevertGrayReleaseUrl      = wechatApiUrl + "/wxa/revertgrayrelease?access_token=%s"
+	ChangeVisitStatusUrl      = wechatApiUrl + "/wxa/change_visitstatus?access_token=%s"
+	WeappSupportVersionUrl    = wechatApiUrl + "/cgi-bin/wxopen/getweappsupportversion?access_token=%s"
+	SetWeappSupportVersionUrl = wechatApiUrl + "/cgi-bin/wxopen/setweappsupportversion?access_token=%s"
+	VersionInfoUrl            = wechatApiUrl + "/wxa/getversioninfo?access_token=%s"
+)
+
+type VisitStatus string
+
+// 线上服务状态
+const (
+	VisitStatusOpen  VisitStatus = "open"
+	VisitStatusClose VisitStatus = "close"
+)
+
+type ReleaseResponse struct {
+	core.Error
+}
+
+// 发布已通过审核的小程序
+func (srv *Server) Release(authorizerAppid string) (*ReleaseResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ReleaseResponse{}
+	err = srv.PostJson(getCompleteUrl(ReleaseUrl, accessToken), core.H{}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// 版本回退, appVersion为0时回退到上一个版本, 可以通过 HistoryVersion 获取可回退的版本
+func (srv *Server) RevertCodeRelease(authorizerAppid string, appVersion int64) (*ReleaseResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	uri := getCompleteUrl(RevertCodeReleaseUrl, accessToken)
+	if appVersion != 0 {
+		uri += "&app_version=" + strconv.FormatInt(appVersion, 10)
+	}
+	resp := &ReleaseResponse{}
+	err = srv.GetJson(uri, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type HistoryVersion struct {
+	// 发布的版本号
+	AppVersion  int64  `json:"app_version"`
+	UserVersion string `json:"user_version"`
+	UserDesc    string `json:"user_desc"`
+	CommitTime  int64  `json:"commit_time"`
+}
+
+type HistoryVersionResponse struct {
+	core.Error
+	VersionList []HistoryVersion `json:"version_list"`
+}
+
+// 获取可回退的小程序版本
+func (srv *Server) HistoryVersion(authorizerAppid string) (*HistoryVersionResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	resp := &HistoryVersionResponse{}
+	err = srv.GetJson(getCompleteUrl(RevertCodeReleaseUrl, accessToken)+"&action=get_history_version", resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type GrayReleaseRequest struct {
+	// 灰度的百分比, 1 ~ 100
+	GrayPercentage int `json:"gray_percentage"`
+}
+
+// 分阶段发布
+func (srv *Server) GrayRelease(authorizerAppid string, grayPercentage int) (*ReleaseResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	req := GrayReleaseRequest{
+		GrayPercentage: grayPercentage,
+	}
+	resp := &ReleaseResponse{}
+	err = srv.PostJson(getCompleteUrl(GrayReleaseUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type GrayReleasePlanResponse struct {
+	core.Error
+	GrayReleasePlan struct {
+		// 0 初始状态, 1 执行中, 2 暂停中, 3 执行完毕, 4 被删除
+		Status          int   `json:"status"`
+		CreateTimestamp int64 `json:"create_timestamp"`
+		GrayPercentage  int   `json:"gray_percentage"`
+	} `json:"gray_release_plan"`
+}
+
+// 查询当前分阶段发布详情
+func (srv *Server) GrayReleasePlan(authorizerAppid string) (*GrayReleasePlanResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	resp := &GrayReleasePlanResponse{}
+	err = srv.GetJson(getCompleteUrl(GrayReleasePlanUrl, accessToken), resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// 取消分阶段发布
+func (srv *Server) RevertGrayRelease(authorizerAppid string) (*ReleaseResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ReleaseResponse{}
+	err = srv.GetJson(getCompleteUrl(RevertGrayReleaseUrl, accessToken), resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type ChangeVisitStatusRequest struct {
+	Action VisitStatus `json:"action"`
+}
+
+// 修改小程序线上代码的可见状态
+func (srv *Server) ChangeVisitStatus(authorizerAppid string, status VisitStatus) (*ReleaseResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	req := ChangeVisitStatusRequest{
+		Action: status,
+	}
+	resp := &ReleaseResponse{}
+	err = srv.PostJson(getCompleteUrl(ChangeVisitStatusUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type WeappSupportVersionResponse struct {
+	core.Error
+	// 当前设置的最低基础库版本
+	NowVersion string `json:"now_version"`
+	// 各版本的用户占比
+	UvInfo struct {
+		Items []struct {
+			Percentage float64 `json:"percentage"`
+			Version    string  `json:"version"`
+		} `json:"items"`
+	} `json:"uv_info"`
+}
+
+// 查询当前设置的最低基础库版本及各版本用户占比
+func (srv *Server) WeappSupportVersion(authorizerAppid string) (*WeappSupportVersionResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	resp := &WeappSupportVersionResponse{}
+	err = srv.PostJson(getCompleteUrl(WeappSupportVersionUrl, accessToken), core.H{}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type SetWeappSupportVersionRequest struct {
+	Version string `json:"version"`
+}
+
+// 设置最低基础库版本
+func (srv *Server) SetWeappSupportVersion(authorizerAppid, version string) (*ReleaseResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	req := SetWeappSupportVersionRequest{
+		Version: version,
+	}
+	resp := &ReleaseResponse{}
+	err = srv.PostJson(getCompleteUrl(SetWeappSupportVersionUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// 体验版信息
+type ExpVersionInfo struct {
+	ExpTime    int64  `json:"exp_time"`
+	ExpVersion string `json:"exp_version"`
+	ExpDesc    string `json:"exp_desc"`
+}
+
+// 线上版信息
+type ReleaseVersionInfo struct {
+	ReleaseTime    int64  `json:"release_time"`
+	ReleaseVersion string `json:"release_version"`
+	ReleaseDesc    string `json:"release_desc"`
+}
+
+type VersionInfoResponse struct {
+	core.Error
+	ExpInfo     ExpVersionInfo     `json:"exp_info"`
+	ReleaseInfo ReleaseVersionInfo `json:"release_info"`
+}
+
+// 查询小程序体验版和线上版的版本信息
+func (srv *Server) VersionInfo(authorizerAppid string) (*VersionInfoResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	resp := &VersionInfoResponse{}
+	err = srv.PostJson(getCompleteUrl(VersionInfoUrl, accessToken), core.H{}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
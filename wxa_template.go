@@ -0,0 +1,135 @@
+package open_wechat
+
+import "github.com/owen-gxz/open-wechat/core"
+
+// 代码模板库管理, 使用第三方平台token
+const (
+	TemplateDraftListUrl = wechatApiUrl + "/wxa/gettemplatedraftlist?access_token=%s"
+	AddToTemplateUrl     = wechatApiUrl + "/wxa/addtotemplate?access_token=%s"
+	TemplateListUrl      = wechatApiUrl + "/wxa/gettemplatelist?access_token=%s"
+	DeleteTemplateUrl    = wechatApiUrl + "/wxa/deletetemplate?access_token=%s"
+)
+
+type TemplateType int
+
+// 模板类型
+const (
+	TemplateTypeNormal   TemplateType = 0 // 普通模板
+	TemplateTypeStandard TemplateType = 1 // 标准模板
+)
+
+// 开发者工具上传的草稿
+type TemplateDraft struct {
+	DraftId     int64  `json:"draft_id"`
+	CreateTime  int64  `json:"create_time"`
+	UserVersion string `json:"user_version"`
+	UserDesc    string `json:"user_desc"`
+	// 上传草稿的小程序
+	SourceMiniprogramAppid string `json:"source_miniprogram_appid"`
+	SourceMiniprogram      string `json:"source_miniprogram"`
+	// 上传草稿的开发者
+	Developer string `json:"developer"`
+}
+
+type TemplateDraftListResponse struct {
+	core.Error
+	DraftList []TemplateDraft `json:"draft_list"`
+}
+
+// 获取代码草稿列表
+func (srv *Server) TemplateDraftList() (*TemplateDraftListResponse, error) {
+	accessToken, err := srv.Token()
+	if err != nil {
+		return nil, err
+	}
+	resp := &TemplateDraftListResponse{}
+	err = srv.GetJson(getCompleteUrl(TemplateDraftListUrl, accessToken), resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type AddToTemplateRequest struct {
+	DraftId      int64        `json:"draft_id"`
+	TemplateType TemplateType `json:"template_type"`
+}
+
+type TemplateResponse struct {
+	core.Error
+}
+
+// 将草稿添加到代码模板库, 标准模板需要审核, 结果见 TemplateList 的AuditStatus
+func (srv *Server) AddToTemplate(draftId int64, templateType TemplateType) (*TemplateResponse, error) {
+	accessToken, err := srv.Token()
+	if err != nil {
+		return nil, err
+	}
+	req := AddToTemplateRequest{
+		DraftId:      draftId,
+		TemplateType: templateType,
+	}
+	resp := &TemplateResponse{}
+	err = srv.PostJson(getCompleteUrl(AddToTemplateUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// 代码模板
+type Template struct {
+	TemplateId   int64        `json:"template_id"`
+	TemplateType TemplateType `json:"template_type"`
+	CreateTime   int64        `json:"create_time"`
+	UserVersion  string       `json:"user_version"`
+	UserDesc     string       `json:"user_desc"`
+	// 来源的小程序
+	SourceMiniprogramAppid string `json:"source_miniprogram_appid"`
+	SourceMiniprogram      string `json:"source_miniprogram"`
+	Developer              string `json:"developer"`
+	// 标准模板的审核状态
+	AuditStatus int `json:"audit_status"`
+	// 标准模板审核驳回的原因
+	Reason string `json:"reason"`
+}
+
+type TemplateListResponse struct {
+	core.Error
+	TemplateList []Template `json:"template_list"`
+}
+
+// 获取代码模板列表
+func (srv *Server) TemplateList() (*TemplateListResponse, error) {
+	accessToken, err := srv.Token()
+	if err != nil {
+		return nil, err
+	}
+	resp := &TemplateListResponse{}
+	err = srv.GetJson(getCompleteUrl(TemplateListUrl, accessToken), resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type DeleteTemplateRequest struct {
+	TemplateId int64 `json:"template_id"`
+}
+
+// 删除代码模板
+func (srv *Server) DeleteTemplate(templateId int64) (*TemplateResponse, error) {
+	accessToken, err := srv.Token()
+	if err != nil {
+		return nil, err
+	}
+	req := DeleteTemplateRequest{
+		TemplateId: templateId,
+	}
+	resp := &TemplateResponse{}
+	err = srv.PostJson(getCompleteUrl(DeleteTemplateUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,94 @@
+package open_wechat
+
+import "github.com/owen-gxz/open-wechat/core"
+
+// 代小程序体验者管理, 使用授权方token
+const (
+	BindTesterUrl   = wechatApiUrl + "/wxa/bind_tester?access_token=%s"
+	UnbindTesterUrl = wechatApiUrl + "/wxa/unbind_tester?access_token=%s"
+	MemberAuthUrl   = wechatApiUrl + "/wxa/memberauth?access_token=%s"
+)
+
+type TesterRequest struct {
+	// 体验者微信号
+	Wechatid string `json:"wechatid,omitempty"`
+	// 绑定时返回的人员对应的唯一字符串
+	Userstr string `json:"userstr,omitempty"`
+}
+
+type BindTesterResponse struct {
+	core.Error
+	// 用于解绑
+	Userstr string `json:"userstr"`
+}
+
+// 绑定体验者
+func (srv *Server) BindTester(authorizerAppid, wechatid string) (*BindTesterResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	req := TesterRequest{
+		Wechatid: wechatid,
+	}
+	resp := &BindTesterResponse{}
+	err = srv.PostJson(getCompleteUrl(BindTesterUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type UnbindTesterResponse struct {
+	core.Error
+}
+
+// 解绑体验者, wechatid和userstr填写一个即可
+func (srv *Server) UnbindTester(authorizerAppid, wechatid, userstr string) (*UnbindTesterResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	req := TesterRequest{
+		Wechatid: wechatid,
+		Userstr:  userstr,
+	}
+	resp := &UnbindTesterResponse{}
+	err = srv.PostJson(getCompleteUrl(UnbindTesterUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type MemberAuthRequest struct {
+	Action string `json:"action"`
+}
+
+// 体验者
+type Member struct {
+	// 人员对应的唯一字符串
+	Userstr string `json:"userstr"`
+}
+
+type MemberAuthResponse struct {
+	core.Error
+	Members []Member `json:"members"`
+}
+
+// 获取体验者列表
+func (srv *Server) MemberAuth(authorizerAppid string) (*MemberAuthResponse, error) {
+	accessToken, err := srv.authorizerTokenFor(authorizerAppid, FuncScopeWxaDevelop)
+	if err != nil {
+		return nil, err
+	}
+	req := MemberAuthRequest{
+		Action: "get_experiencer",
+	}
+	resp := &MemberAuthResponse{}
+	err = srv.PostJson(getCompleteUrl(MemberAuthUrl, accessToken), req, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}